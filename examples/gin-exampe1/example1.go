@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rafapcarvalho/logtracer/examples/gin-exampe1/file1"
 	logger "github.com/rafapcarvalho/logtracer/pkg/logtracer"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -27,11 +28,17 @@ func main() {
 		//},
 	}
 
-	logger.InitLogger(cfg)
+	if err := logger.InitLogger(cfg); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
 
 	r := gin.New()
-	r.Use(logger.GinMiddleware(cfg.ServiceName))
+	ginCfg := logger.DefaultGinConfig()
+	ginCfg.SkipPaths = []string{"/healthz"}
 	r.Use(logger.OTELMiddleware(cfg.ServiceName))
+	r.Use(logger.GinMiddlewareWithConfig(ginCfg))
+	r.Use(logger.GinRecovery())
+	r.Any("/loglevel", gin.WrapH(logger.LevelHandler(logger.WithRevertAfter(15*time.Minute))))
 
 	r.GET("/example", func(c *gin.Context) {
 		customid := uuid.New().String()
@@ -6,6 +6,7 @@ import (
 	logger "github.com/rafapcarvalho/logtracer/pkg/logtracer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"log"
 	"os"
 	"time"
 )
@@ -18,9 +19,11 @@ func main() {
 		OTLPEndpoint:  "localhost:4318",
 	}
 
-	logger.InitLogger(cfg)
+	if err := logger.InitLogger(cfg); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
 
-	logTracer := &logger.LogTracer{}
+	logTracer := logger.Default()
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -5,6 +5,7 @@ import (
 	pb "github.com/rafapcarvalho/logtracer/examples/grpc-example1/proto"
 	logger "github.com/rafapcarvalho/logtracer/pkg/logtracer"
 	"google.golang.org/grpc"
+	"log"
 	"net"
 )
 
@@ -28,7 +29,9 @@ func main() {
 		OTLPEndpoint:  "localhost:4318",
 	}
 
-	logger.InitLogger(cfg)
+	if err := logger.InitLogger(cfg); err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
@@ -36,13 +39,14 @@ func main() {
 		return
 	}
 
-	logTracer := &logger.LogTracer{}
+	logTracer := logger.Default()
 	unaryInterceptor, _ := logger.OTELGRPCServerInterceptor()
 
 	s := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			logTracer.UnaryServerInterceptor(),
 			unaryInterceptor,
+			logTracer.UnaryServerRecoveryInterceptor(),
 		),
 	)
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -9,23 +10,59 @@ import (
 
 var LoggerLevel = new(slog.LevelVar)
 
+const (
+	LevelTrace = slog.Level(-8)
+	LevelFatal = slog.Level(12)
+)
+
 func StdoutJSON() slog.Handler {
-	return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	return JSON(os.Stdout, LoggerLevel, nil)
+}
+
+func StdoutTXT() slog.Handler {
+	return TXT(os.Stdout, LoggerLevel, nil)
+}
+
+// JSON returns a JSON handler writing to w at level that applies redactor to
+// every attribute. redactor may be nil.
+func JSON(w io.Writer, level slog.Leveler, redactor *Redactor) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{
 		AddSource:   true,
-		Level:       LoggerLevel,
-		ReplaceAttr: replace,
+		Level:       level,
+		ReplaceAttr: replaceWith(redactor),
 	})
 }
 
-func StdoutTXT() slog.Handler {
-	return slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+// TXT returns a text handler writing to w at level that applies redactor to
+// every attribute. redactor may be nil.
+func TXT(w io.Writer, level slog.Leveler, redactor *Redactor) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{
 		AddSource:   true,
-		Level:       LoggerLevel,
-		ReplaceAttr: replace,
+		Level:       level,
+		ReplaceAttr: replaceWith(redactor),
 	})
 }
 
+func replaceWith(redactor *Redactor) func([]string, slog.Attr) slog.Attr {
+	if redactor == nil {
+		return replace
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		return redactor.ReplaceAttr(groups, replace(groups, a))
+	}
+}
+
 func replace(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			switch level {
+			case LevelTrace:
+				return slog.String(slog.LevelKey, "TRACE")
+			case LevelFatal:
+				return slog.String(slog.LevelKey, "FATAL")
+			}
+		}
+	}
 	if a.Key == slog.SourceKey {
 		if src, ok := a.Value.Any().(*slog.Source); ok {
 			function := filepath.Base(src.Function) // Pega apenas o nome da função, sem o pacote
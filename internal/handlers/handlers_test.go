@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"testing"
 )
 
@@ -14,3 +15,9 @@ func TestStdoutTXT(t *testing.T) {
 	handler := StdoutTXT()
 	assert.NotNil(t, handler)
 }
+
+func TestReplaceLevelNames(t *testing.T) {
+	assert.Equal(t, "TRACE", replace(nil, slog.Any(slog.LevelKey, LevelTrace)).Value.String())
+	assert.Equal(t, "FATAL", replace(nil, slog.Any(slog.LevelKey, LevelFatal)).Value.String())
+	assert.Equal(t, slog.LevelInfo, replace(nil, slog.Any(slog.LevelKey, slog.LevelInfo)).Value.Any())
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultMask = "[REDACTED]"
+	redactTag   = "logtracer"
+	maxDepth    = 8
+)
+
+// Rule masks the substrings of string values matched by Pattern. When Valid is
+// set, a match is only masked if Valid returns true (e.g. a Luhn check).
+type Rule struct {
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool
+}
+
+// Redactor masks attribute values by key name, by value pattern and by the
+// `logtracer:"redact"` struct tag. A nil *Redactor leaves values untouched.
+type Redactor struct {
+	keys  map[string]struct{}
+	rules []Rule
+	mask  string
+}
+
+func NewRedactor(keys []string, rules []Rule, mask string) *Redactor {
+	if mask == "" {
+		mask = DefaultMask
+	}
+	r := &Redactor{
+		keys:  make(map[string]struct{}, len(keys)),
+		rules: rules,
+		mask:  mask,
+	}
+	for _, k := range keys {
+		r.keys[strings.ToLower(k)] = struct{}{}
+	}
+	return r
+}
+
+// ReplaceAttr redacts a single slog attribute; built-in keys are kept as is.
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if r == nil {
+		return a
+	}
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey:
+			return a
+		}
+	}
+
+	if r.sensitiveKey(a.Key) {
+		return slog.String(a.Key, r.mask)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.redactString(a.Value.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, r.Redact(a.Key, a.Value.Any()))
+	}
+	return a
+}
+
+// Redact returns v with the redaction rules applied, using key as its name.
+func (r *Redactor) Redact(key string, v any) any {
+	if r == nil {
+		return v
+	}
+	return r.redact(key, v, 0)
+}
+
+func (r *Redactor) redact(key string, v any, depth int) any {
+	if r.sensitiveKey(key) {
+		return r.mask
+	}
+
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.redactString(value)
+	case error:
+		if s := value.Error(); r.redactString(s) != s {
+			return r.redactString(s)
+		}
+		return v
+	}
+
+	if depth >= maxDepth {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return v
+		}
+		rv = rv.Elem()
+	}
+
+	// Tagged fields are masked even when the type formats itself, since its
+	// String or MarshalJSON method would print them.
+	tagged := hasRedactTags(rv.Type())
+	if !tagged {
+		switch v.(type) {
+		case slog.LogValuer, json.Marshaler, encoding.TextMarshaler, fmt.Stringer:
+			return v
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if !r.hasRules() && !tagged {
+			return v
+		}
+		return r.redactStruct(rv, depth)
+	case reflect.Map:
+		if (!r.hasRules() && !tagged) || rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			out[k] = r.redact(k, iter.Value().Interface(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if (!r.hasRules() && !tagged) || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = r.redact(key, rv.Index(i).Interface(), depth+1)
+		}
+		return out
+	}
+	return v
+}
+
+// redactStruct converts a struct into a map keyed by the JSON field names,
+// masking fields tagged `logtracer:"redact"`.
+func (r *Redactor) redactStruct(rv reflect.Value, depth int) map[string]any {
+	t := rv.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Tag.Get(redactTag) == "redact" {
+			out[name] = r.mask
+			continue
+		}
+		out[name] = r.redact(name, rv.Field(i).Interface(), depth+1)
+	}
+	return out
+}
+
+func (r *Redactor) hasRules() bool {
+	return len(r.keys) > 0 || len(r.rules) > 0
+}
+
+func (r *Redactor) sensitiveKey(key string) bool {
+	_, ok := r.keys[strings.ToLower(key)]
+	return ok
+}
+
+func (r *Redactor) redactString(s string) string {
+	for _, rule := range r.rules {
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.Valid != nil && !rule.Valid(match) {
+				return match
+			}
+			return r.mask
+		})
+	}
+	return s
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+var redactTagsCache sync.Map
+
+// hasRedactTags reports whether t, or a struct reachable from its fields,
+// carries a `logtracer:"redact"` tag.
+func hasRedactTags(t reflect.Type) bool {
+	if cached, ok := redactTagsCache.Load(t); ok {
+		return cached.(bool)
+	}
+	found := scanRedactTags(t, map[reflect.Type]bool{})
+	redactTagsCache.Store(t, found)
+	return found
+}
+
+func scanRedactTags(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get(redactTag) == "redact" || scanRedactTags(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Street string `json:"street"`
+	Number string `json:"number" logtracer:"redact"`
+}
+
+type user struct {
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Password string   `json:"-"`
+	Document string   `json:"document" logtracer:"redact"`
+	Address  *address `json:"address"`
+}
+
+type account struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret" logtracer:"redact"`
+}
+
+func (a account) String() string {
+	return a.ID + ":" + a.Secret
+}
+
+func TestRedactorReplaceAttr(t *testing.T) {
+	email := regexp.MustCompile(`[a-z]+@[a-z]+\.com`)
+	r := NewRedactor([]string{"Password"}, []Rule{{Pattern: email}}, "")
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want any
+	}{
+		{"Sensitive key", slog.String("password", "hunter2"), DefaultMask},
+		{"Sensitive key with number", slog.Int("PASSWORD", 1234), DefaultMask},
+		{"Pattern in string", slog.String("note", "mail to john@example.com now"), "mail to [REDACTED] now"},
+		{"Pattern in error", slog.Any("error", errors.New("user john@example.com not found")), "user [REDACTED] not found"},
+		{"Untouched", slog.Int("count", 3), int64(3)},
+		{"Built-in key", slog.String(slog.MessageKey, "john@example.com"), "john@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.ReplaceAttr(nil, tt.attr)
+			assert.Equal(t, tt.want, got.Value.Any())
+		})
+	}
+}
+
+func TestRedactorStructTags(t *testing.T) {
+	u := user{
+		Name:     "John",
+		Email:    "john@example.com",
+		Password: "hunter2",
+		Document: "123",
+		Address:  &address{Street: "Main", Number: "42"},
+	}
+
+	t.Run("Tags only", func(t *testing.T) {
+		r := NewRedactor(nil, nil, "***")
+		got := r.Redact("user", u)
+		assert.Equal(t, map[string]any{
+			"name":     "John",
+			"email":    "john@example.com",
+			"document": "***",
+			"address":  map[string]any{"street": "Main", "number": "***"},
+		}, got)
+	})
+
+	t.Run("Plain struct without rules is untouched", func(t *testing.T) {
+		plain := struct{ Name string }{"John"}
+		assert.Equal(t, plain, NewRedactor(nil, nil, "").Redact("user", plain))
+	})
+
+	t.Run("Tags win over String", func(t *testing.T) {
+		got := NewRedactor(nil, nil, "").Redact("account", account{ID: "a1", Secret: "s3cr3t"})
+		assert.Equal(t, map[string]any{"id": "a1", "secret": DefaultMask}, got)
+	})
+
+	t.Run("Untagged Stringer is untouched", func(t *testing.T) {
+		now := time.Now()
+		assert.Equal(t, now, NewRedactor([]string{"token"}, nil, "").Redact("at", now))
+	})
+
+	t.Run("Nil redactor", func(t *testing.T) {
+		var r *Redactor
+		assert.Equal(t, u, r.Redact("user", u))
+	})
+}
+
+func TestJSONWithRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRedactor([]string{"token"}, nil, "")
+	logger := slog.New(JSON(&buf, LoggerLevel, r))
+
+	logger.Info("login", "token", "abc", "user", user{Name: "John", Document: "123"})
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, DefaultMask, got["token"])
+	assert.Equal(t, DefaultMask, got["user"].(map[string]any)["document"])
+	assert.Equal(t, "John", got["user"].(map[string]any)["name"])
+}
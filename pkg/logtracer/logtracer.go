@@ -1,19 +1,21 @@
 package logtracer
 
 import (
+	"context"
 	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"os"
 	"strings"
+	"sync"
 )
 
 var (
-	ginLog     *CategoryLogger
 	grpcLog    *CategoryLogger
-	noTrace    *CategoryLogger
 	InitLog    *CategoryLogger
 	CfgLog     *CategoryLogger
 	SrvcLog    *CategoryLogger
@@ -25,46 +27,164 @@ var (
 	globalTracer  trace.Tracer
 	traceProvider *sdktrace.TracerProvider
 	propagator    propagation.TextMapPropagator
+	stopStats     func()
 	// shutdownOnce  sync.Once
+
+	std *LogTracer
+
+	// fallback serves zero value instances used before InitLogger.
+	fallback     *LogTracer
+	fallbackOnce sync.Once
 )
 
-func InitLogger(cfg Config) {
+// New builds a self-contained LogTracer with its own logger, level, tracer
+// provider, propagator and category loggers. It does not touch the package
+// globals nor the OpenTelemetry globals, so several instances can coexist in
+// one process. An invalid Config is reported as a *ConfigError.
+func New(cfg Config) (*LogTracer, error) {
+	return newInstance(cfg, new(slog.LevelVar))
+}
+
+func newInstance(cfg Config, level *slog.LevelVar) (*LogTracer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	lt := newLogTracer(cfg, level)
+	if cfg.EnableTracing {
+		if err := lt.initTracing(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return lt, nil
+}
+
+// InitLogger initializes the default LogTracer and exposes it through the
+// package level loggers (InitLog, SrvcLog, ...) and the OpenTelemetry globals.
+// The default instance uses the package level set with SetLevel. On error the
+// previous default instance is left untouched.
+func InitLogger(cfg Config) error {
+	lt, err := newInstance(cfg, handlers.LoggerLevel)
+	if err != nil {
+		return err
+	}
+	setDefault(lt)
+	return nil
+}
+
+// Default returns the LogTracer created by InitLogger, or nil if InitLogger
+// was never called.
+func Default() *LogTracer {
+	return std
+}
+
+func setDefault(lt *LogTracer) {
+	if fallback != nil {
+		adoptCategories(fallback, lt)
+	}
+	std = lt
+
+	InitLog = lt.InitLog
+	CfgLog = lt.CfgLog
+	SrvcLog = lt.SrvcLog
+	TstLog = lt.TstLog
+	grpcLog = lt.grpcLog
+	NoTrace = lt.NoTrace
+	Categories = lt.Categories
+	customID = lt.customID
+
+	globalTracer = lt.tracer
+	traceProvider = lt.tracerProvider
+	propagator = lt.propagator
+	stopStats = lt.stopExportStats
+
+	if lt.tracerProvider != nil {
+		otel.SetErrorHandler(&errorLogger{log: lt.SrvcLog})
+		otel.SetTracerProvider(lt.tracerProvider)
+		otel.SetTextMapPropagator(lt.propagator)
+	}
+}
+
+func newLogTracer(cfg Config, level *slog.LevelVar) *LogTracer {
+	redactor := newRedactor(cfg.Redaction)
 	var logger *slog.Logger
 	logFormat := strings.ToLower(cfg.LogFormat)
 	if logFormat == "json" {
-		logger = slog.New(handlers.StdoutJSON())
+		logger = slog.New(handlers.JSON(os.Stdout, level, redactor))
 	} else {
-		logger = slog.New(handlers.StdoutTXT())
+		logger = slog.New(handlers.TXT(os.Stdout, level, redactor))
 	}
 
-	if cfg.EnableTracing {
-		var err error
-		traceProvider, err = initTracerProvider(cfg)
-		if err == nil {
-			globalTracer = traceProvider.Tracer(cfg.ServiceName)
-			otel.SetTracerProvider(traceProvider)
-
-			propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
-			otel.SetTextMapPropagator(propagator)
-		} else {
-			logger.Error("Failed to initialize tracer provider", "error", err)
-		}
+	lt := &LogTracer{
+		logger:      logger,
+		level:       level,
+		redactor:    redactor,
+		serviceName: cfg.ServiceName,
+		customID:    CustomID(cfg.CustomID),
+		Categories:  make(map[string]*CategoryLogger),
+	}
+
+	// cfg.Propagators was checked by Config.Validate.
+	lt.propagator, _ = newPropagator(cfg.Propagators)
+
+	lt.InitLog = lt.RegisterCategory("INIT")
+	lt.CfgLog = lt.RegisterCategory("CFG")
+	lt.SrvcLog = lt.RegisterCategory("SRVC")
+	lt.TstLog = lt.RegisterCategory("TEST")
+	lt.ginLog = lt.RegisterCategory("GIN")
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+	lt.noTrace = lt.RegisterCategory("WithoutTrace")
+	lt.NoTrace = WithoutTracer{cl: lt.noTrace}
+
+	return lt
+}
+
+func (lt *LogTracer) initTracing(cfg Config) error {
+	t, err := initTracerProvider(cfg, func(msg string, args ...any) {
+		lt.InitLog.Warn(context.Background(), msg, args...)
+	})
+	if err != nil {
+		return err
 	}
+	lt.memoryExporter, _ = t.exporter.(*tracetest.InMemoryExporter)
+	lt.tailSampler = t.tail
+	lt.exportCounters = t.counters
+	lt.tracerProvider = t.provider
+	lt.tracer = t.provider.Tracer(cfg.ServiceName)
 
-	if cfg.CustomID != "" {
-		customID = CustomID(cfg.CustomID)
+	if cfg.Batch != nil && cfg.Batch.StatsInterval > 0 && t.exporter != nil {
+		lt.stopStats = make(chan struct{})
+		go lt.logExportStats(cfg.Batch.StatsInterval, lt.stopStats)
 	}
+	return nil
+}
 
-	InitLog = newCategoryLogger(logger, cfg.ServiceName, "INIT")
-	CfgLog = newCategoryLogger(logger, cfg.ServiceName, "CFG")
-	SrvcLog = newCategoryLogger(logger, cfg.ServiceName, "SRVC")
-	TstLog = newCategoryLogger(logger, cfg.ServiceName, "TEST")
-	ginLog = newCategoryLogger(logger, cfg.ServiceName, "GIN")
-	grpcLog = newCategoryLogger(logger, cfg.ServiceName, "GRPC")
-	noTrace = newCategoryLogger(logger, cfg.ServiceName, "WithoutTrace")
-	NoTrace = WithoutTracer{}
+// orDefault lets a zero value LogTracer (e.g. &LogTracer{}) fall back to the
+// default instance created by InitLogger or, before InitLogger, to a text
+// logger without tracing.
+func (lt *LogTracer) orDefault() *LogTracer {
+	if lt.logger != nil {
+		return lt
+	}
+	return defaultOrFallback()
+}
+
+// defaultOrFallback returns the default instance or, before InitLogger, a
+// shared text logger without tracing.
+func defaultOrFallback() *LogTracer {
+	if std != nil {
+		return std
+	}
+	fallbackOnce.Do(func() {
+		fallback = newLogTracer(Config{}, handlers.LoggerLevel)
+	})
+	return fallback
+}
 
-	Categories = make(map[string]*CategoryLogger)
+func (lt *LogTracer) newCategoryLogger(category string) *CategoryLogger {
+	cl := newCategoryLogger(lt.logger, lt.serviceName, category)
+	cl.lt = lt
+	return cl
 }
 
 func newCategoryLogger(logger *slog.Logger, serviceName, category string) *CategoryLogger {
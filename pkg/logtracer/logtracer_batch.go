@@ -0,0 +1,175 @@
+package logtracer
+
+import (
+	"context"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// BatchConfig tunes the batch span processor used by the exporters. Zero
+// fields keep the OpenTelemetry defaults, including the OTEL_BSP_* variables.
+type BatchConfig struct {
+	MaxQueueSize       int
+	MaxExportBatchSize int
+	ExportInterval     time.Duration
+	ExportTimeout      time.Duration
+	// BlockOnQueueFull makes ending a span wait for room in the queue
+	// instead of dropping it.
+	BlockOnQueueFull bool
+	// StatsInterval logs the ExportStats through the INIT category at this
+	// interval. Zero disables it.
+	StatsInterval time.Duration
+}
+
+// ExportStats counts the spans handled by the exporter since start.
+type ExportStats struct {
+	Exported uint64
+	// Dropped spans did not fit in the batch queue.
+	Dropped uint64
+	// Failed spans were part of a batch the exporter returned an error for.
+	Failed uint64
+}
+
+type exportCounters struct {
+	received, exported, dropped, failed atomic.Uint64
+}
+
+func (c *exportCounters) stats() ExportStats {
+	return ExportStats{
+		Exported: c.exported.Load(),
+		Dropped:  c.dropped.Load(),
+		Failed:   c.failed.Load(),
+	}
+}
+
+// countingExporter counts the spans exported and the ones that failed.
+type countingExporter struct {
+	sdktrace.SpanExporter
+	counters *exportCounters
+}
+
+func (e *countingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.counters.failed.Add(uint64(len(spans)))
+	} else {
+		e.counters.exported.Add(uint64(len(spans)))
+	}
+	return err
+}
+
+// queueGuard drops and counts the spans the batch processor would otherwise
+// drop silently once its queue is full. Spans waiting in the queue or in the
+// batch being exported both count against the queue size, so the batch
+// processor itself never overflows.
+type queueGuard struct {
+	sdktrace.SpanProcessor
+	counters *exportCounters
+	size     uint64
+	// stopped is set on Shutdown; the batch processor discards the spans
+	// ended afterwards, so they must not count as in flight.
+	stopped atomic.Bool
+}
+
+func (g *queueGuard) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() || g.stopped.Load() {
+		return
+	}
+	c := g.counters
+	if c.received.Add(1)-c.exported.Load()-c.failed.Load() > g.size {
+		c.received.Add(^uint64(0))
+		c.dropped.Add(1)
+		return
+	}
+	g.SpanProcessor.OnEnd(s)
+}
+
+func (g *queueGuard) Shutdown(ctx context.Context) error {
+	g.stopped.Store(true)
+	return g.SpanProcessor.Shutdown(ctx)
+}
+
+// newBatchProcessor wraps the sdk batch span processor with the counters.
+func newBatchProcessor(exporter sdktrace.SpanExporter, cfg *BatchConfig, counters *exportCounters) sdktrace.SpanProcessor {
+	var bc BatchConfig
+	if cfg != nil {
+		bc = *cfg
+	}
+
+	queueSize := bc.MaxQueueSize
+	if queueSize <= 0 {
+		queueSize = envInt("OTEL_BSP_MAX_QUEUE_SIZE", sdktrace.DefaultMaxQueueSize)
+	}
+	opts := []sdktrace.BatchSpanProcessorOption{sdktrace.WithMaxQueueSize(queueSize)}
+	if bc.MaxExportBatchSize > 0 {
+		opts = append(opts, sdktrace.WithMaxExportBatchSize(bc.MaxExportBatchSize))
+	}
+	if bc.ExportInterval > 0 {
+		opts = append(opts, sdktrace.WithBatchTimeout(bc.ExportInterval))
+	}
+	if bc.ExportTimeout > 0 {
+		opts = append(opts, sdktrace.WithExportTimeout(bc.ExportTimeout))
+	}
+
+	exporter = &countingExporter{SpanExporter: exporter, counters: counters}
+	if bc.BlockOnQueueFull {
+		return sdktrace.NewBatchSpanProcessor(exporter, append(opts, sdktrace.WithBlocking())...)
+	}
+	processor := sdktrace.NewBatchSpanProcessor(exporter, opts...)
+	return &queueGuard{SpanProcessor: processor, counters: counters, size: uint64(queueSize)}
+}
+
+func envInt(key string, defaultValue int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return defaultValue
+}
+
+// ExportStats returns the exporter counters of this instance, or zero stats
+// when tracing is disabled.
+func (lt *LogTracer) ExportStats() ExportStats {
+	if lt.exportCounters == nil {
+		return ExportStats{}
+	}
+	return lt.exportCounters.stats()
+}
+
+// stopExportStats stops the goroutine started for BatchConfig.StatsInterval.
+func (lt *LogTracer) stopExportStats() {
+	if lt.stopStats != nil {
+		lt.stopStatsOnce.Do(func() { close(lt.stopStats) })
+	}
+}
+
+// logExportStats logs the ExportStats through the INIT category every
+// interval until stop is closed, as a warning when spans were dropped or
+// failed since the previous line.
+func (lt *LogTracer) logExportStats(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last ExportStats
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		stats := lt.ExportStats()
+		level := LevelInfo
+		if stats.Dropped > last.Dropped || stats.Failed > last.Failed {
+			level = LevelWarn
+		}
+		lt.InitLog.Log(context.Background(), level, "Trace export stats",
+			"exported", stats.Exported,
+			"dropped", stats.Dropped,
+			"failed", stats.Failed,
+		)
+		last = stats
+	}
+}
@@ -0,0 +1,145 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingExporter holds every export until release is closed.
+type blockingExporter struct {
+	tracetest.InMemoryExporter
+	release chan struct{}
+}
+
+func (e *blockingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	<-e.release
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+type failingExporter struct {
+	tracetest.InMemoryExporter
+}
+
+func (e *failingExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return errors.New("collector unavailable")
+}
+
+func TestBatchProcessorCountsDroppedSpans(t *testing.T) {
+	exporter := &blockingExporter{release: make(chan struct{})}
+	counters := &exportCounters{}
+	processor := newBatchProcessor(exporter, &BatchConfig{MaxQueueSize: 2, MaxExportBatchSize: 1, ExportInterval: time.Millisecond}, counters)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+
+	for i := 0; i < 5; i++ {
+		_, span := tp.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+	}
+	assert.Equal(t, ExportStats{Dropped: 3}, counters.stats())
+
+	close(exporter.release)
+	assert.NoError(t, tp.ForceFlush(context.Background()))
+	assert.Equal(t, ExportStats{Exported: 2, Dropped: 3}, counters.stats())
+	assert.Len(t, exporter.GetSpans(), 2)
+	assert.NoError(t, tp.Shutdown(context.Background()))
+}
+
+func TestBatchProcessorIgnoresSpansAfterShutdown(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	counters := &exportCounters{}
+	processor := newBatchProcessor(exporter, &BatchConfig{MaxQueueSize: 2}, counters)
+	assert.NoError(t, processor.Shutdown(context.Background()))
+
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled})
+	for i := 0; i < 5; i++ {
+		processor.OnEnd(tracetest.SpanStub{Name: "late", SpanContext: sampled}.Snapshot())
+	}
+	assert.Equal(t, ExportStats{}, counters.stats())
+	assert.Zero(t, counters.received.Load())
+}
+
+func TestBatchProcessorBlocking(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	counters := &exportCounters{}
+	processor := newBatchProcessor(exporter, &BatchConfig{MaxQueueSize: 1, MaxExportBatchSize: 1, BlockOnQueueFull: true}, counters)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+
+	for i := 0; i < 5; i++ {
+		_, span := tp.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+	}
+	assert.NoError(t, tp.Shutdown(context.Background()))
+	assert.Equal(t, ExportStats{Exported: 5}, counters.stats())
+}
+
+func TestExportStatsFailed(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, SpanExporter: &failingExporter{}, Batch: &BatchConfig{}})
+	assert.NoError(t, err)
+
+	EndSpan(lt.StartSpan(context.Background(), "operation"))
+	assert.NoError(t, lt.Shutdown(context.Background()))
+	assert.Equal(t, ExportStats{Failed: 1}, lt.ExportStats())
+}
+
+// syncBuffer is a bytes.Buffer safe for a logging goroutine and a reader.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogExportStats(t *testing.T) {
+	var buf syncBuffer
+	counters := &exportCounters{}
+	counters.exported.Add(7)
+	counters.dropped.Add(2)
+	lt := &LogTracer{
+		InitLog:        newCategoryLogger(slog.New(slog.NewJSONHandler(&buf, nil)), "test-service", "INIT"),
+		exportCounters: counters,
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		lt.logExportStats(time.Millisecond, stop)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return buf.String() != "" }, time.Second, time.Millisecond)
+	close(stop)
+	<-done
+
+	checkLogOutput(t, buf.String(), `{"level":"WARN","msg":"Trace export stats","category":"INIT","exported":7,"dropped":2,"failed":0}`)
+}
+
+func TestShutdownStopsExportStats(t *testing.T) {
+	assert.NoError(t, InitLogger(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterMemory, Batch: &BatchConfig{StatsInterval: time.Hour}}))
+	stop := Default().stopStats
+
+	assert.NoError(t, Shutdown(context.Background()))
+	select {
+	case <-stop:
+	default:
+		t.Fatal("export stats still running after Shutdown")
+	}
+	// A second Shutdown must not close the channel again.
+	assert.NoError(t, Shutdown(context.Background()))
+}
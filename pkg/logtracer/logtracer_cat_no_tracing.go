@@ -8,45 +8,74 @@ import (
 	"time"
 )
 
-func (WithoutTracer) Info(ctx context.Context, msg string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelInfo, msg, args...)
+func (w WithoutTracer) Info(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelInfo, msg, args...)
 }
 
-func (WithoutTracer) Error(ctx context.Context, msg string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelError, msg, args...)
+func (w WithoutTracer) Error(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelError, msg, args...)
 }
 
-func (WithoutTracer) Warn(ctx context.Context, msg string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelWarn, msg, args...)
+func (w WithoutTracer) Warn(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelWarn, msg, args...)
 }
 
-func (WithoutTracer) Debug(ctx context.Context, msg string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelDebug, msg, args...)
+func (w WithoutTracer) Debug(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelDebug, msg, args...)
 }
 
-func (WithoutTracer) Infof(ctx context.Context, format string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelInfo, fmt.Sprintf(format, args...))
+func (w WithoutTracer) Trace(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelTrace, msg, args...)
 }
 
-func (WithoutTracer) Errorf(ctx context.Context, format string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelError, fmt.Sprintf(format, args...))
+// Fatal logs at LevelFatal, flushes the exporters and exits the process.
+func (w WithoutTracer) Fatal(ctx context.Context, msg string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelFatal, msg, args...)
+	w.logger().exit(ctx)
 }
 
-func (WithoutTracer) Warnf(ctx context.Context, format string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelWarn, fmt.Sprintf(format, args...))
+func (w WithoutTracer) Infof(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelInfo, fmt.Sprintf(format, args...))
 }
 
-func (WithoutTracer) Debugf(ctx context.Context, format string, args ...any) {
-	noTrace.executeNoTrace(ctx, LevelDebug, fmt.Sprintf(format, args...))
+func (w WithoutTracer) Errorf(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelError, fmt.Sprintf(format, args...))
+}
+
+func (w WithoutTracer) Warnf(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (w WithoutTracer) Debugf(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (w WithoutTracer) Tracef(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelTrace, fmt.Sprintf(format, args...))
+}
+
+func (w WithoutTracer) Fatalf(ctx context.Context, format string, args ...any) {
+	w.logger().executeNoTrace(ctx, LevelFatal, fmt.Sprintf(format, args...))
+	w.logger().exit(ctx)
+}
+
+// logger returns the category logger bound to w, falling back to the default
+// instance, or to a text logger before InitLogger, for the zero value.
+func (w WithoutTracer) logger() *CategoryLogger {
+	if w.cl != nil {
+		return w.cl
+	}
+	return defaultOrFallback().noTrace
 }
 
 func (cl *CategoryLogger) executeNoTrace(ctx context.Context, level LogLevel, msg string, args ...any) {
+	cl = cl.resolve()
 
 	var slogLevel = getLogLevel(level)
-	if !cl.logger.Enabled(ctx, slogLevel) {
+	if !cl.enabled(ctx, slogLevel) {
 		return
 	}
-	id := getOrCreateTraceID(ctx)
+	id := getOrCreateTraceID(ctx, cl.customIDKey())
 
 	var pcs [1]uintptr
 	runtime.Callers(3, pcs[:])
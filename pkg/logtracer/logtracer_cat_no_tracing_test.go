@@ -2,6 +2,7 @@ package logtracer
 
 import (
 	"context"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -17,6 +18,7 @@ func TestWithoutTracer(t *testing.T) {
 		{"Error", NoTrace.Error},
 		{"Warn", NoTrace.Warn},
 		{"Debug", NoTrace.Debug},
+		{"Trace", NoTrace.Trace},
 	}
 
 	for _, tt := range tests {
@@ -33,6 +35,7 @@ func TestWithoutTracer(t *testing.T) {
 		{"Errorf", NoTrace.Errorf},
 		{"Warnf", NoTrace.Warnf},
 		{"Debugf", NoTrace.Debugf},
+		{"Tracef", NoTrace.Tracef},
 	}
 
 	for _, tt := range tests2 {
@@ -41,3 +44,11 @@ func TestWithoutTracer(t *testing.T) {
 		})
 	}
 }
+
+func TestWithoutTracerZeroValueBeforeInitLogger(t *testing.T) {
+	previous := std
+	std = nil
+	defer func() { std = previous }()
+
+	assert.NotPanics(t, func() { WithoutTracer{}.Info(context.Background(), "before init") })
+}
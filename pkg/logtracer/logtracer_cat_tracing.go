@@ -3,16 +3,30 @@ package logtracer
 import (
 	"context"
 	"fmt"
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"os"
 	"runtime"
+	"sync/atomic"
 	"time"
 )
 
 type CategoryLogger struct {
 	logger *slog.Logger
+	lt     *LogTracer
+	level  atomic.Pointer[LogLevel]
+	// attrs are the WithCategoryAttribute pairs, kept to register the
+	// category again on the default instance.
+	attrs []any
+	// target is the category of the default instance that a category
+	// registered before InitLogger writes through once InitLogger ran.
+	target atomic.Pointer[CategoryLogger]
 }
 
-type WithoutTracer struct{}
+type WithoutTracer struct {
+	cl *CategoryLogger
+}
 
 func (cl *CategoryLogger) Info(ctx context.Context, msg string, args ...any) {
 	cl.execute(ctx, LevelInfo, msg, args...)
@@ -30,6 +44,21 @@ func (cl *CategoryLogger) Debug(ctx context.Context, msg string, args ...any) {
 	cl.execute(ctx, LevelDebug, msg, args...)
 }
 
+func (cl *CategoryLogger) Trace(ctx context.Context, msg string, args ...any) {
+	cl.execute(ctx, LevelTrace, msg, args...)
+}
+
+// Fatal logs at LevelFatal, flushes the exporters and exits the process.
+func (cl *CategoryLogger) Fatal(ctx context.Context, msg string, args ...any) {
+	cl.execute(ctx, LevelFatal, msg, args...)
+	cl.exit(ctx)
+}
+
+// Log logs msg at the given level, for callers that pick the level at runtime.
+func (cl *CategoryLogger) Log(ctx context.Context, level LogLevel, msg string, args ...any) {
+	cl.execute(ctx, level, msg, args...)
+}
+
 func (cl *CategoryLogger) Infof(ctx context.Context, format string, args ...any) {
 	cl.execute(ctx, LevelInfo, fmt.Sprintf(format, args...))
 }
@@ -46,13 +75,23 @@ func (cl *CategoryLogger) Debugf(ctx context.Context, format string, args ...any
 	cl.execute(ctx, LevelDebug, fmt.Sprintf(format, args...))
 }
 
+func (cl *CategoryLogger) Tracef(ctx context.Context, format string, args ...any) {
+	cl.execute(ctx, LevelTrace, fmt.Sprintf(format, args...))
+}
+
+func (cl *CategoryLogger) Fatalf(ctx context.Context, format string, args ...any) {
+	cl.execute(ctx, LevelFatal, fmt.Sprintf(format, args...))
+	cl.exit(ctx)
+}
+
 func (cl *CategoryLogger) execute(ctx context.Context, level LogLevel, msg string, args ...any) {
+	cl = cl.resolve()
 
 	var slogLevel = getLogLevel(level)
-	if !cl.logger.Enabled(ctx, slogLevel) {
+	if !cl.enabled(ctx, slogLevel) {
 		return
 	}
-	id := getOrCreateTraceID(ctx)
+	id := getOrCreateTraceID(ctx, cl.customIDKey())
 
 	var pcs [1]uintptr
 	runtime.Callers(3, pcs[:])
@@ -63,7 +102,46 @@ func (cl *CategoryLogger) execute(ctx context.Context, level LogLevel, msg strin
 	r.Add(args...)
 	_ = cl.logger.Handler().Handle(ctx, r)
 
-	if globalTracer != nil {
-		recordLogSpan(ctx, level, msg, args...)
+	if cl.tracingEnabled() {
+		recordLogSpan(ctx, level, cl.redactor(), msg, args...)
+	}
+}
+
+// tracingEnabled reports whether the owning LogTracer has a tracer. Category
+// loggers built without an owner fall back to the default instance globals.
+func (cl *CategoryLogger) tracingEnabled() bool {
+	if cl.lt != nil {
+		return cl.lt.tracer != nil
+	}
+	return globalTracer != nil
+}
+
+func (cl *CategoryLogger) redactor() *handlers.Redactor {
+	if cl.lt != nil {
+		return cl.lt.redactor
+	}
+	return nil
+}
+
+func (cl *CategoryLogger) customIDKey() CustomID {
+	if cl.lt != nil {
+		return cl.lt.customID
+	}
+	return customID
+}
+
+var exitFunc = os.Exit
+
+// exit ends the span in ctx, which holds the fatal line, and flushes the
+// tracer provider owning cl before terminating the process.
+func (cl *CategoryLogger) exit(ctx context.Context) {
+	cl = cl.resolve()
+	trace.SpanFromContext(ctx).End()
+	ctx = context.WithoutCancel(ctx)
+	if cl.lt != nil {
+		_ = cl.lt.Shutdown(ctx)
+	} else {
+		_ = Shutdown(ctx)
 	}
+	exitFunc(1)
 }
@@ -19,6 +19,7 @@ func TestCategoryLogger(t *testing.T) {
 		{"Error", logger.Error},
 		{"Warn", logger.Warn},
 		{"Debug", logger.Debug},
+		{"Trace", logger.Trace},
 	}
 
 	for _, tt := range tests {
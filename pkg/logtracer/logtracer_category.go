@@ -0,0 +1,107 @@
+package logtracer
+
+type CategoryOption func(*CategoryOptions)
+
+type CategoryOptions struct {
+	Attributes []any
+	Level      *LogLevel
+}
+
+// WithCategoryAttribute adds a key/value pair to every line logged by the category.
+func WithCategoryAttribute(key string, value any) CategoryOption {
+	return func(o *CategoryOptions) {
+		o.Attributes = append(o.Attributes, key, value)
+	}
+}
+
+// WithCategoryLevel sets the initial level of the category instead of
+// inheriting the global one.
+func WithCategoryLevel(level LogLevel) CategoryOption {
+	return func(o *CategoryOptions) {
+		o.Level = &level
+	}
+}
+
+// RegisterCategory registers a category on the default instance. Before
+// InitLogger the category is registered on a fallback instance that logs text
+// to stdout without tracing, so the returned logger is never nil; InitLogger
+// then registers it on the default instance, which the returned logger and its
+// level follow from then on.
+func RegisterCategory(name string, opts ...CategoryOption) *CategoryLogger {
+	return defaultOrFallback().RegisterCategory(name, opts...)
+}
+
+// Category looks up a category registered on the default instance.
+func Category(name string) (*CategoryLogger, bool) {
+	if std == nil {
+		return nil, false
+	}
+	return std.Category(name)
+}
+
+// RegisterCategory returns the logger for the named category, creating it on
+// the first call. Later calls return the same logger and ignore opts.
+// It is safe for concurrent use; the Categories map must not be mutated
+// directly while RegisterCategory may run.
+func (lt *LogTracer) RegisterCategory(name string, opts ...CategoryOption) *CategoryLogger {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	if cl, ok := lt.Categories[name]; ok {
+		return cl
+	}
+
+	options := &CategoryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	cl := lt.newCategoryLogger(name)
+	if len(options.Attributes) > 0 {
+		cl.logger = cl.logger.With(options.Attributes...)
+		cl.attrs = options.Attributes
+	}
+	if options.Level != nil {
+		cl.SetLevel(*options.Level)
+	}
+	lt.Categories[name] = cl
+	return cl
+}
+
+// Category looks up a registered category, including the built-in ones
+// (INIT, CFG, SRVC, TEST, GIN, GRPC).
+func (lt *LogTracer) Category(name string) (*CategoryLogger, bool) {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	cl, ok := lt.Categories[name]
+	return cl, ok
+}
+
+// resolve returns the category cl writes through: its target on the default
+// instance when cl was registered before InitLogger, cl itself otherwise.
+func (cl *CategoryLogger) resolve() *CategoryLogger {
+	if target := cl.target.Load(); target != nil {
+		return target
+	}
+	return cl
+}
+
+// adoptCategories registers on lt the categories of the fallback instance,
+// i.e. those registered before InitLogger, and points them at lt. A level
+// set on them carries over unless lt already overrides it.
+func adoptCategories(from, lt *LogTracer) {
+	from.mu.RLock()
+	defer from.mu.RUnlock()
+
+	for name, cl := range from.Categories {
+		attrs := cl.attrs
+		target := lt.RegisterCategory(name, func(o *CategoryOptions) { o.Attributes = attrs })
+		if level, ok := cl.Level(); ok {
+			if _, set := target.Level(); !set {
+				target.SetLevel(level)
+			}
+		}
+		cl.target.Store(target)
+	}
+}
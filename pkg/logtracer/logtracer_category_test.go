@@ -0,0 +1,94 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestRegisterCategory(t *testing.T) {
+	var buf bytes.Buffer
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+		serviceName: "test-service",
+		Categories:  make(map[string]*CategoryLogger),
+	}
+
+	billing := lt.RegisterCategory("BILLING", WithCategoryAttribute("module", "billing"))
+	assert.Same(t, billing, lt.RegisterCategory("BILLING"))
+
+	found, ok := lt.Category("BILLING")
+	assert.True(t, ok)
+	assert.Same(t, billing, found)
+
+	_, ok = lt.Category("AUTH")
+	assert.False(t, ok)
+
+	billing.Info(context.Background(), "test message")
+	checkLogOutput(t, buf.String(), `{"level":"INFO","msg":"test message","component":"test-service","category":"BILLING","module":"billing"}`)
+}
+
+func TestRegisterCategoryConcurrent(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	loggers := make([]*CategoryLogger, 20)
+	for i := range loggers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loggers[i] = lt.RegisterCategory("QUEUE")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, cl := range loggers {
+		assert.Same(t, loggers[0], cl)
+	}
+
+	grpc, ok := lt.Category("GRPC")
+	assert.True(t, ok)
+	assert.Same(t, lt.grpcLog, grpc)
+}
+
+func TestRegisterCategoryBeforeInitLogger(t *testing.T) {
+	previous := std
+	std = nil
+	defer func() { std = previous }()
+
+	queue := RegisterCategory("QUEUE")
+	assert.NotNil(t, queue)
+	assert.Same(t, queue, RegisterCategory("QUEUE"))
+	assert.NotPanics(t, func() {
+		queue.Info(context.Background(), "logged before InitLogger")
+	})
+}
+
+func TestRegisterCategoryBeforeInitLoggerFollowsDefault(t *testing.T) {
+	previous := std
+	std = nil
+	defer func() { std = previous }()
+
+	queue := RegisterCategory("EARLY", WithCategoryAttribute("queue", "orders"), WithCategoryLevel(LevelWarn))
+	assert.NoError(t, InitLogger(Config{ServiceName: "test-service", LogFormat: "json"}))
+
+	adopted, ok := Category("EARLY")
+	assert.True(t, ok)
+	assert.Same(t, Default(), adopted.lt)
+	assert.Same(t, adopted, queue.resolve())
+	assert.IsType(t, &slog.JSONHandler{}, adopted.logger.Handler())
+	assert.Equal(t, []any{"queue", "orders"}, adopted.attrs)
+
+	level, overridden := queue.Level()
+	assert.True(t, overridden)
+	assert.Equal(t, LevelWarn, level)
+
+	assert.NoError(t, SetCategoryLevel("EARLY", LevelDebug))
+	level, _ = queue.Level()
+	assert.Equal(t, LevelDebug, level)
+	assert.NotPanics(t, func() { queue.Info(context.Background(), "logged after InitLogger") })
+}
@@ -0,0 +1,123 @@
+package logtracer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ConfigError describes an invalid Config field detected during initialization.
+type ConfigError struct {
+	Field  string
+	Value  any
+	Reason string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	msg := fmt.Sprintf("logtracer: invalid config %s %q: %s", e.Field, fmt.Sprint(e.Value), e.Reason)
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks the whole Config and returns a *ConfigError for the first
+// invalid field found.
+func (cfg Config) Validate() error {
+	if strings.TrimSpace(cfg.ServiceName) == "" {
+		return &ConfigError{Field: "ServiceName", Value: cfg.ServiceName, Reason: "must not be empty"}
+	}
+
+	switch strings.ToLower(cfg.LogFormat) {
+	case "", "json", "text":
+	default:
+		return &ConfigError{Field: "LogFormat", Value: cfg.LogFormat, Reason: `must be "json" or "text"`}
+	}
+
+	if cfg.EnableTracing && cfg.OTLPEndpoint != "" {
+		if err := validateEndpoint(cfg.OTLPEndpoint); err != nil {
+			return &ConfigError{Field: "OTLPEndpoint", Value: cfg.OTLPEndpoint, Reason: "must be host:port or an http(s) URL", Err: err}
+		}
+	}
+
+	if !validExporter(cfg.Exporter) {
+		return &ConfigError{Field: "Exporter", Value: cfg.Exporter, Reason: "must be otlp-http, otlp-grpc, stdout, file, memory or none"}
+	}
+	if cfg.SpanExporter == nil && strings.EqualFold(cfg.Exporter, ExporterFile) && cfg.ExporterPath == "" {
+		return &ConfigError{Field: "ExporterPath", Value: cfg.ExporterPath, Reason: "must be set for the file exporter"}
+	}
+
+	if o := cfg.OTLP; o != nil {
+		switch strings.ToLower(o.Compression) {
+		case "", "gzip", "none":
+		default:
+			return &ConfigError{Field: "OTLP.Compression", Value: o.Compression, Reason: `must be "gzip" or "none"`}
+		}
+		if strings.EqualFold(o.Compression, "none") && strings.EqualFold(cfg.Exporter, ExporterOTLPGRPC) {
+			return errGRPCNoCompression(o.Compression)
+		}
+		if o.Timeout < 0 {
+			return &ConfigError{Field: "OTLP.Timeout", Value: o.Timeout, Reason: "must not be negative"}
+		}
+		if r := o.Retry; r != nil && (r.InitialInterval < 0 || r.MaxInterval < 0 || r.MaxElapsedTime < 0) {
+			return &ConfigError{Field: "OTLP.Retry", Value: *r, Reason: "intervals must not be negative"}
+		}
+		if o.TLS != nil && (o.TLS.CertFile == "") != (o.TLS.KeyFile == "") {
+			return &ConfigError{Field: "OTLP.TLS", Value: o.TLS.CertFile, Reason: "CertFile and KeyFile must be set together"}
+		}
+	}
+
+	if err := cfg.Sampling.validate(); err != nil {
+		return &ConfigError{Field: "Sampling", Value: cfg.Sampling, Reason: "must describe a known sampler", Err: err}
+	}
+
+	if ts := cfg.TailSampling; ts != nil && (ts.KeepRatio < 0 || ts.KeepRatio > 1) {
+		return &ConfigError{Field: "TailSampling.KeepRatio", Value: ts.KeepRatio, Reason: "must be between 0 and 1"}
+	}
+
+	if b := cfg.Batch; b != nil {
+		if b.MaxQueueSize < 0 || b.MaxExportBatchSize < 0 || b.ExportInterval < 0 || b.ExportTimeout < 0 || b.StatsInterval < 0 {
+			return &ConfigError{Field: "Batch", Value: *b, Reason: "sizes and durations must not be negative"}
+		}
+		if b.MaxQueueSize > 0 && b.MaxExportBatchSize > b.MaxQueueSize {
+			return &ConfigError{Field: "Batch.MaxExportBatchSize", Value: b.MaxExportBatchSize, Reason: "must not exceed MaxQueueSize"}
+		}
+	}
+
+	if _, err := newPropagator(cfg.Propagators); err != nil {
+		return &ConfigError{Field: "Propagators", Value: cfg.Propagators, Reason: "must list known propagators", Err: err}
+	}
+
+	return nil
+}
+
+func validateEndpoint(endpoint string) error {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("missing host")
+		}
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package logtracer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		field string
+	}{
+		{
+			name: "Valid config",
+			cfg:  Config{ServiceName: "test-service", LogFormat: "JSON", EnableTracing: true, OTLPEndpoint: "localhost:4318"},
+		},
+		{
+			name: "Valid endpoint URL",
+			cfg:  Config{ServiceName: "test-service", EnableTracing: true, OTLPEndpoint: "https://collector:4318/v1/traces"},
+		},
+		{
+			name:  "Missing service name",
+			cfg:   Config{LogFormat: "json"},
+			field: "ServiceName",
+		},
+		{
+			name:  "Unknown log format",
+			cfg:   Config{ServiceName: "test-service", LogFormat: "xml"},
+			field: "LogFormat",
+		},
+		{
+			name:  "Endpoint without port",
+			cfg:   Config{ServiceName: "test-service", EnableTracing: true, OTLPEndpoint: "localhost"},
+			field: "OTLPEndpoint",
+		},
+		{
+			name:  "Unknown exporter",
+			cfg:   Config{ServiceName: "test-service", EnableTracing: true, Exporter: "zipkin"},
+			field: "Exporter",
+		},
+		{
+			name:  "File exporter without path",
+			cfg:   Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterFile},
+			field: "ExporterPath",
+		},
+		{
+			name: "Memory exporter",
+			cfg:  Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterMemory},
+		},
+		{
+			name:  "Unknown OTLP compression",
+			cfg:   Config{ServiceName: "test-service", OTLP: &OTLPConfig{Compression: "zstd"}},
+			field: "OTLP.Compression",
+		},
+		{
+			name:  "No compression for OTLP gRPC",
+			cfg:   Config{ServiceName: "test-service", Exporter: ExporterOTLPGRPC, OTLP: &OTLPConfig{Compression: "none"}},
+			field: "OTLP.Compression",
+		},
+		{
+			name: "No compression for OTLP HTTP",
+			cfg:  Config{ServiceName: "test-service", Exporter: ExporterOTLPHTTP, OTLP: &OTLPConfig{Compression: "none"}},
+		},
+		{
+			name:  "Negative OTLP retry interval",
+			cfg:   Config{ServiceName: "test-service", OTLP: &OTLPConfig{Retry: &RetryConfig{Enabled: true, MaxInterval: -time.Second}}},
+			field: "OTLP.Retry",
+		},
+		{
+			name:  "OTLP client certificate without key",
+			cfg:   Config{ServiceName: "test-service", OTLP: &OTLPConfig{TLS: &TLSConfig{CertFile: "cert.pem"}}},
+			field: "OTLP.TLS",
+		},
+		{
+			name:  "Batch size above queue size",
+			cfg:   Config{ServiceName: "test-service", Batch: &BatchConfig{MaxQueueSize: 10, MaxExportBatchSize: 20}},
+			field: "Batch.MaxExportBatchSize",
+		},
+		{
+			name:  "Negative batch interval",
+			cfg:   Config{ServiceName: "test-service", Batch: &BatchConfig{ExportInterval: -time.Second}},
+			field: "Batch",
+		},
+		{
+			name:  "Endpoint with unsupported scheme",
+			cfg:   Config{ServiceName: "test-service", EnableTracing: true, OTLPEndpoint: "ftp://collector:4318"},
+			field: "OTLPEndpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var cfgErr *ConfigError
+			assert.True(t, errors.As(err, &cfgErr))
+			assert.Equal(t, tt.field, cfgErr.Field)
+		})
+	}
+}
+
+func TestInitLoggerInvalidConfig(t *testing.T) {
+	assert.NoError(t, InitLogger(Config{ServiceName: "test-service", LogFormat: "json"}))
+	previous := SrvcLog
+
+	err := InitLogger(Config{LogFormat: "json"})
+	var cfgErr *ConfigError
+	assert.True(t, errors.As(err, &cfgErr))
+	assert.Same(t, previous, SrvcLog)
+
+	_, err = New(Config{ServiceName: "test-service", LogFormat: "yaml"})
+	assert.True(t, errors.As(err, &cfgErr))
+	assert.Equal(t, "LogFormat", cfgErr.Field)
+}
+
+func TestConfigValidatePropagators(t *testing.T) {
+	err := Config{ServiceName: "test-service", Propagators: []string{"b3", "xray"}}.Validate()
+	var cfgErr *ConfigError
+	assert.True(t, errors.As(err, &cfgErr))
+	assert.Equal(t, "Propagators", cfgErr.Field)
+
+	assert.NoError(t, Config{ServiceName: "test-service", Propagators: []string{"tracecontext", "b3multi"}}.Validate())
+}
@@ -0,0 +1,110 @@
+package logtracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"os"
+	"strings"
+)
+
+// Exporter kinds accepted in Config.Exporter.
+const (
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterStdout   = "stdout"
+	ExporterFile     = "file"
+	ExporterMemory   = "memory"
+	ExporterNone     = "none"
+)
+
+func validExporter(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "", ExporterOTLPHTTP, ExporterOTLPGRPC, ExporterStdout, ExporterFile, ExporterMemory, ExporterNone:
+		return true
+	}
+	return false
+}
+
+// newSpanExporter builds the exporter selected by cfg. It returns a nil
+// exporter for ExporterNone.
+func newSpanExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	if cfg.SpanExporter != nil {
+		return cfg.SpanExporter, nil
+	}
+
+	switch cfg.exporterKind() {
+	case ExporterOTLPHTTP:
+		opts, err := otlpHTTPOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter for endpoint %q: %w", cfg.OTLPEndpoint, err)
+		}
+		return exporter, nil
+	case ExporterOTLPGRPC:
+		opts, err := otlpGRPCOptions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC exporter for endpoint %q: %w", cfg.OTLPEndpoint, err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.ExporterPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file %q: %w", cfg.ExporterPath, err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &fileExporter{SpanExporter: exporter, f: f}, nil
+	case ExporterMemory:
+		return tracetest.NewInMemoryExporter(), nil
+	case ExporterNone:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+}
+
+// fileExporter writes spans as JSON lines and closes the file on shutdown.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	f *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.f.Close())
+}
+
+// RecordedSpans returns the spans kept by the default instance when it uses
+// the memory exporter, see LogTracer.RecordedSpans.
+func RecordedSpans() tracetest.SpanStubs {
+	if std == nil {
+		return nil
+	}
+	return std.RecordedSpans()
+}
+
+// RecordedSpans returns the spans ended so far when the instance uses the
+// memory exporter (or a *tracetest.InMemoryExporter as Config.SpanExporter),
+// and nil otherwise.
+func (lt *LogTracer) RecordedSpans() tracetest.SpanStubs {
+	if lt.memoryExporter == nil {
+		return nil
+	}
+	return lt.memoryExporter.GetSpans()
+}
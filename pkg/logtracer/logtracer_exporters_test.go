@@ -0,0 +1,94 @@
+package logtracer
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSpanExporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantType any
+	}{
+		{"Default", Config{}, &otlptrace.Exporter{}},
+		{"OTLP HTTP", Config{Exporter: ExporterOTLPHTTP, OTLPEndpoint: "localhost:4318"}, &otlptrace.Exporter{}},
+		{"OTLP gRPC", Config{Exporter: ExporterOTLPGRPC, OTLPEndpoint: "localhost:4317"}, &otlptrace.Exporter{}},
+		{"OTLP gRPC URL", Config{Exporter: "OTLP-GRPC", OTLPEndpoint: "http://localhost:4317"}, &otlptrace.Exporter{}},
+		{"Memory", Config{Exporter: ExporterMemory}, &tracetest.InMemoryExporter{}},
+		{"File", Config{Exporter: ExporterFile, ExporterPath: filepath.Join(t.TempDir(), "spans.json")}, &fileExporter{}},
+		{"None", Config{Exporter: ExporterNone}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newSpanExporter(context.Background(), tt.cfg)
+			assert.NoError(t, err)
+			if tt.wantType == nil {
+				assert.Nil(t, exporter)
+				return
+			}
+			assert.IsType(t, tt.wantType, exporter)
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+
+	_, err := newSpanExporter(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestMemoryExporter(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterMemory})
+	assert.NoError(t, err)
+	defer func() { _ = lt.Shutdown(context.Background()) }()
+
+	ctx := lt.StartSpan(context.Background(), "operation")
+	lt.SrvcLog.Info(ctx, "inside span")
+	EndSpan(ctx)
+
+	spans := lt.RecordedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "operation", spans[0].Name)
+	assert.Len(t, spans[0].Events, 1)
+}
+
+func TestCustomSpanExporter(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterNone, SpanExporter: exporter})
+	assert.NoError(t, err)
+
+	EndSpan(lt.StartSpan(context.Background(), "operation"))
+	assert.Len(t, exporter.GetSpans(), 1)
+	assert.Len(t, lt.RecordedSpans(), 1)
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterFile, ExporterPath: path})
+	assert.NoError(t, err)
+
+	EndSpan(lt.StartSpan(context.Background(), "operation"))
+	assert.NoError(t, lt.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"operation"`)
+}
+
+func TestNoneExporter(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterNone})
+	assert.NoError(t, err)
+
+	ctx := lt.StartSpan(context.Background(), "operation")
+	// Spans still get IDs for the log lines and propagation.
+	assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+	EndSpan(ctx)
+	assert.Nil(t, lt.RecordedSpans())
+	assert.NoError(t, lt.Shutdown(context.Background()))
+}
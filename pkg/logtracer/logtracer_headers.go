@@ -0,0 +1,89 @@
+package logtracer
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"strings"
+)
+
+const maskedValue = "[REDACTED]"
+
+// defaultSensitiveHeaders are always masked, whatever the configuration says.
+var defaultSensitiveHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+}
+
+type capturedHeader struct {
+	name   string
+	values []string
+}
+
+// captureHeaders returns the allowed headers found through get, with the
+// values of sensitive ones masked. Names are matched case-insensitively and
+// returned in lower case.
+func captureHeaders(get func(name string) []string, allow, sensitive []string) []capturedHeader {
+	if len(allow) == 0 {
+		return nil
+	}
+
+	headers := make([]capturedHeader, 0, len(allow))
+	for _, name := range allow {
+		name = strings.ToLower(name)
+		values := get(name)
+		if len(values) == 0 {
+			continue
+		}
+		if isSensitiveHeader(name, sensitive) {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = maskedValue
+			}
+			values = masked
+		}
+		headers = append(headers, capturedHeader{name: name, values: values})
+	}
+	return headers
+}
+
+func isSensitiveHeader(name string, sensitive []string) bool {
+	for _, s := range defaultSensitiveHeaders {
+		if name == s {
+			return true
+		}
+	}
+	for _, s := range sensitive {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// headersGroup renders headers as a slog group, joining repeated values.
+func headersGroup(key string, headers []capturedHeader) slog.Attr {
+	attrs := make([]any, 0, len(headers))
+	for _, h := range headers {
+		attrs = append(attrs, slog.String(h.name, strings.Join(h.values, ", ")))
+	}
+	return slog.Group(key, attrs...)
+}
+
+// setHeaderSpanAttributes sets one <prefix>.<name> attribute per header on the
+// active span, e.g. http.request.header.content-type.
+func setHeaderSpanAttributes(ctx context.Context, prefix string, headers []capturedHeader) {
+	span := trace.SpanFromContext(ctx)
+	if len(headers) == 0 || !span.IsRecording() {
+		return
+	}
+	attrs := make([]attribute.KeyValue, 0, len(headers))
+	for _, h := range headers {
+		attrs = append(attrs, attribute.StringSlice(prefix+"."+h.name, h.values))
+	}
+	span.SetAttributes(attrs...)
+}
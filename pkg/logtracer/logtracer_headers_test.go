@@ -0,0 +1,30 @@
+package logtracer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCaptureHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Tenant-Token", "tenant-secret")
+	header.Add("Accept", "text/plain")
+	header.Add("Accept", "application/json")
+
+	headers := captureHeaders(header.Values,
+		[]string{"Content-Type", "authorization", "X-Tenant-Token", "Accept", "X-Missing"},
+		[]string{"x-tenant-token"},
+	)
+
+	assert.Equal(t, []capturedHeader{
+		{name: "content-type", values: []string{"application/json"}},
+		{name: "authorization", values: []string{maskedValue}},
+		{name: "x-tenant-token", values: []string{maskedValue}},
+		{name: "accept", values: []string{"text/plain", "application/json"}},
+	}, headers)
+
+	assert.Nil(t, captureHeaders(header.Values, nil, nil))
+}
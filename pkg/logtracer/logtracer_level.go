@@ -1,50 +1,191 @@
 package logtracer
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"log/slog"
+	"strings"
 )
 
+// LogLevel is ordered from the most to the least verbose level, so levels can
+// be compared directly (e.g. level >= LevelError). The zero value is LevelInfo.
 type LogLevel int
 
 const (
-	LevelInfo LogLevel = iota
-	LevelError
-	LevelWarn
-	LevelDebug
+	LevelTrace LogLevel = -8
+	LevelDebug LogLevel = -4
+	LevelInfo  LogLevel = 0
+	LevelWarn  LogLevel = 4
+	LevelError LogLevel = 8
+	LevelFatal LogLevel = 12
 )
 
 func (l LogLevel) String() string {
-	return [...]string{"Info", "Error", "Warn", "Debug"}[l]
-}
-func SetLevel(level LogLevel) {
-	var slogLevel slog.Level
-	switch level {
+	switch l {
+	case LevelTrace:
+		return "Trace"
+	case LevelDebug:
+		return "Debug"
 	case LevelInfo:
-		slogLevel = slog.LevelInfo
-	case LevelError:
-		slogLevel = slog.LevelError
+		return "Info"
 	case LevelWarn:
-		slogLevel = slog.LevelWarn
-	case LevelDebug:
-		slogLevel = slog.LevelDebug
+		return "Warn"
+	case LevelError:
+		return "Error"
+	case LevelFatal:
+		return "Fatal"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// SetLevel sets the global level, used by the default instance and by the
+// loggers in use before InitLogger. Instances built with New have their own
+// level, see LogTracer.SetLevel.
+func SetLevel(level LogLevel) {
+	handlers.LoggerLevel.Set(getLogLevel(level))
+}
+
+// GetLevel returns the current global log level.
+func GetLevel() LogLevel {
+	return toLogLevel(handlers.LoggerLevel.Level())
+}
+
+// SetLevel sets the level of this instance.
+func (lt *LogTracer) SetLevel(level LogLevel) {
+	lt.levelVar().Set(getLogLevel(level))
+}
+
+// GetLevel returns the current level of this instance.
+func (lt *LogTracer) GetLevel() LogLevel {
+	return toLogLevel(lt.levelVar().Level())
+}
+
+// levelVar returns the instance level, or the global one for instances not
+// built by New or InitLogger.
+func (lt *LogTracer) levelVar() *slog.LevelVar {
+	if lt.level == nil {
+		return handlers.LoggerLevel
+	}
+	return lt.level
+}
+
+func toLogLevel(level slog.Level) LogLevel {
+	switch {
+	case level >= handlers.LevelFatal:
+		return LevelFatal
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	case level >= slog.LevelDebug:
+		return LevelDebug
 	default:
-		slogLevel = slog.LevelInfo
+		return LevelTrace
+	}
+}
+
+// ParseLevel converts a level name such as "debug" or "WARN" into a LogLevel.
+func ParseLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
 	}
-	handlers.LoggerLevel.Set(slogLevel)
+	return LevelInfo, fmt.Errorf("logtracer: unknown log level %q", s)
 }
 
 func getLogLevel(level LogLevel) slog.Level {
 	var newLevel slog.Level
 	switch level {
-	case LevelError:
-		newLevel = slog.LevelError
-	case LevelWarn:
-		newLevel = slog.LevelWarn
+	case LevelTrace:
+		newLevel = handlers.LevelTrace
 	case LevelDebug:
 		newLevel = slog.LevelDebug
+	case LevelWarn:
+		newLevel = slog.LevelWarn
+	case LevelError:
+		newLevel = slog.LevelError
+	case LevelFatal:
+		newLevel = handlers.LevelFatal
 	default:
 		newLevel = slog.LevelInfo
 	}
 	return newLevel
 }
+
+var ErrUnknownCategory = errors.New("logtracer: unknown category")
+
+// SetCategoryLevel overrides the level of a category on the default instance.
+func SetCategoryLevel(category string, level LogLevel) error {
+	if std == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+	return std.SetCategoryLevel(category, level)
+}
+
+// ResetCategoryLevel makes a category of the default instance inherit the global level again.
+func ResetCategoryLevel(category string) error {
+	if std == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+	return std.ResetCategoryLevel(category)
+}
+
+func (lt *LogTracer) SetCategoryLevel(category string, level LogLevel) error {
+	cl, ok := lt.Category(category)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+	cl.SetLevel(level)
+	return nil
+}
+
+func (lt *LogTracer) ResetCategoryLevel(category string) error {
+	cl, ok := lt.Category(category)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCategory, category)
+	}
+	cl.ResetLevel()
+	return nil
+}
+
+// SetLevel overrides the global level for this category only.
+func (cl *CategoryLogger) SetLevel(level LogLevel) {
+	cl = cl.resolve()
+	cl.level.Store(&level)
+}
+
+// ResetLevel drops the category override so the global level applies again.
+func (cl *CategoryLogger) ResetLevel() {
+	cl = cl.resolve()
+	cl.level.Store(nil)
+}
+
+// Level returns the category level and whether it overrides the global one.
+func (cl *CategoryLogger) Level() (LogLevel, bool) {
+	cl = cl.resolve()
+	if level := cl.level.Load(); level != nil {
+		return *level, true
+	}
+	return 0, false
+}
+
+func (cl *CategoryLogger) enabled(ctx context.Context, level slog.Level) bool {
+	if override := cl.level.Load(); override != nil {
+		return level >= getLogLevel(*override)
+	}
+	return cl.logger.Enabled(ctx, level)
+}
@@ -0,0 +1,263 @@
+package logtracer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const levelInherit = "inherit"
+
+type LevelHandlerOption func(*LevelHandlerOptions)
+
+type LevelHandlerOptions struct {
+	// RevertAfter restores the previous levels once elapsed when a request
+	// does not set revert_after itself. Zero keeps changes until the next one.
+	RevertAfter time.Duration
+}
+
+// WithRevertAfter reverts every level change made through the handler after d.
+func WithRevertAfter(d time.Duration) LevelHandlerOption {
+	return func(o *LevelHandlerOptions) {
+		o.RevertAfter = d
+	}
+}
+
+// LevelResponse is the body returned by the level handler.
+type LevelResponse struct {
+	Level      string            `json:"level"`
+	Categories map[string]string `json:"categories"`
+	RevertAt   *time.Time        `json:"revert_at,omitempty"`
+}
+
+// LevelRequest is the body accepted by the level handler on PUT and POST.
+// Categories set to "inherit" (or "") go back to the instance level.
+type LevelRequest struct {
+	Level       string            `json:"level,omitempty"`
+	Categories  map[string]string `json:"categories,omitempty"`
+	RevertAfter string            `json:"revert_after,omitempty"`
+}
+
+type levelSnapshot struct {
+	global     LogLevel
+	categories map[string]*LogLevel
+}
+
+type levelHandler struct {
+	lt      *LogTracer
+	options LevelHandlerOptions
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	baseline *levelSnapshot
+	revertAt time.Time
+	// generation counts the changes; a timer only reverts the change that
+	// armed it, since Stop cannot cancel a callback already running.
+	generation uint64
+}
+
+// LevelHandler returns an http.Handler bound to the default instance that
+// reports its level and per-category levels on GET and changes them on
+// PUT or POST. With Gin it can be mounted with r.Any(path, gin.WrapH(h)).
+func LevelHandler(opts ...LevelHandlerOption) http.Handler {
+	return newLevelHandler(nil, opts...)
+}
+
+// LevelHandler returns an http.Handler bound to this instance, see LevelHandler.
+// It only changes the levels of this instance.
+func (lt *LogTracer) LevelHandler(opts ...LevelHandlerOption) http.Handler {
+	return newLevelHandler(lt, opts...)
+}
+
+func newLevelHandler(lt *LogTracer, opts ...LevelHandlerOption) *levelHandler {
+	options := LevelHandlerOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &levelHandler{lt: lt, options: options}
+}
+
+func (h *levelHandler) tracer() *LogTracer {
+	if h.lt != nil {
+		return h.lt
+	}
+	return std
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	lt := h.tracer()
+	if lt == nil {
+		http.Error(w, "logtracer not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.writeLevels(w, lt)
+	case http.MethodPut, http.MethodPost:
+		var req LevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.apply(lt, req); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrUnknownCategory) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		lt.CfgLog.Info(r.Context(), "Log level changed",
+			"level", req.Level,
+			"categories", req.Categories,
+			"revert_after", req.RevertAfter,
+		)
+		h.writeLevels(w, lt)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *levelHandler) apply(lt *LogTracer, req LevelRequest) error {
+	var global *LogLevel
+	if req.Level != "" {
+		level, err := ParseLevel(req.Level)
+		if err != nil {
+			return err
+		}
+		global = &level
+	}
+
+	categories := make(map[string]*LogLevel, len(req.Categories))
+	for name, value := range req.Categories {
+		if _, ok := lt.Category(name); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, name)
+		}
+		if value == "" || value == levelInherit {
+			categories[name] = nil
+			continue
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		categories[name] = &level
+	}
+
+	revertAfter := h.options.RevertAfter
+	if req.RevertAfter != "" {
+		d, err := time.ParseDuration(req.RevertAfter)
+		if err != nil {
+			return err
+		}
+		revertAfter = d
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.baseline == nil && revertAfter > 0 {
+		h.baseline = snapshotLevels(lt)
+	}
+
+	if global != nil {
+		lt.SetLevel(*global)
+	}
+	for name, level := range categories {
+		if level == nil {
+			_ = lt.ResetCategoryLevel(name)
+		} else {
+			_ = lt.SetCategoryLevel(name, *level)
+		}
+	}
+
+	h.generation++
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	if revertAfter <= 0 {
+		h.baseline = nil
+		h.revertAt = time.Time{}
+		return nil
+	}
+
+	h.revertAt = time.Now().Add(revertAfter)
+	generation := h.generation
+	h.timer = time.AfterFunc(revertAfter, func() { h.revert(lt, generation) })
+	return nil
+}
+
+func (h *levelHandler) revert(lt *LogTracer, generation uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.baseline == nil || generation != h.generation {
+		return
+	}
+	restoreLevels(lt, h.baseline)
+	h.baseline = nil
+	h.timer = nil
+	h.revertAt = time.Time{}
+}
+
+func (h *levelHandler) writeLevels(w http.ResponseWriter, lt *LogTracer) {
+	resp := LevelResponse{
+		Level:      lt.GetLevel().String(),
+		Categories: make(map[string]string),
+	}
+	for name, level := range snapshotLevels(lt).categories {
+		if level == nil {
+			resp.Categories[name] = levelInherit
+		} else {
+			resp.Categories[name] = level.String()
+		}
+	}
+
+	h.mu.Lock()
+	if !h.revertAt.IsZero() {
+		revertAt := h.revertAt
+		resp.RevertAt = &revertAt
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func snapshotLevels(lt *LogTracer) *levelSnapshot {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	snapshot := &levelSnapshot{
+		global:     lt.GetLevel(),
+		categories: make(map[string]*LogLevel, len(lt.Categories)),
+	}
+	for name, cl := range lt.Categories {
+		if level, ok := cl.Level(); ok {
+			snapshot.categories[name] = &level
+		} else {
+			snapshot.categories[name] = nil
+		}
+	}
+	return snapshot
+}
+
+func restoreLevels(lt *LogTracer, snapshot *levelSnapshot) {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	lt.SetLevel(snapshot.global)
+	for name, cl := range lt.Categories {
+		if level := snapshot.categories[name]; level != nil {
+			cl.SetLevel(*level)
+		} else {
+			cl.ResetLevel()
+		}
+	}
+}
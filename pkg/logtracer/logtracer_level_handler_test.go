@@ -0,0 +1,114 @@
+package logtracer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelHandler(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	handler := lt.LevelHandler()
+
+	do := func(method, body string) (*httptest.ResponseRecorder, LevelResponse) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+		var resp LevelResponse
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+		return rec, resp
+	}
+
+	rec, resp := do(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Info", resp.Level)
+	assert.Equal(t, levelInherit, resp.Categories["GRPC"])
+
+	rec, resp = do(http.MethodPut, `{"level":"warn","categories":{"GRPC":"debug"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Warn", resp.Level)
+	assert.Equal(t, "Debug", resp.Categories["GRPC"])
+	assert.Nil(t, resp.RevertAt)
+
+	rec, resp = do(http.MethodPost, `{"categories":{"GRPC":"inherit"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, levelInherit, resp.Categories["GRPC"])
+
+	rec, _ = do(http.MethodPut, `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec, _ = do(http.MethodPut, `{"categories":{"MISSING":"debug"}}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, _ = do(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestLevelHandlerRevertAfter(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	handler := lt.LevelHandler(WithRevertAfter(time.Hour))
+
+	rec := httptest.NewRecorder()
+	body := `{"level":"debug","categories":{"SRVC":"error"},"revert_after":"20ms"}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, LevelDebug, lt.GetLevel())
+
+	assert.Eventually(t, func() bool {
+		_, overridden := lt.SrvcLog.Level()
+		return lt.GetLevel() == LevelInfo && !overridden
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLevelHandlerStaleRevert(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	h := lt.LevelHandler().(*levelHandler)
+	put := func(body string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(body)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	put(`{"level":"debug","revert_after":"1h"}`)
+	stale := h.generation
+	put(`{"level":"warn","revert_after":"1h"}`)
+
+	// A callback of the first timer that was already running when the
+	// second change stopped it must not revert the second change.
+	h.revert(lt, stale)
+	assert.Equal(t, LevelWarn, lt.GetLevel())
+
+	h.revert(lt, h.generation)
+	assert.Equal(t, LevelInfo, lt.GetLevel())
+}
+
+func TestLevelHandlerIsPerInstance(t *testing.T) {
+	first, err := New(Config{ServiceName: "first-service", LogFormat: "json"})
+	assert.NoError(t, err)
+	second, err := New(Config{ServiceName: "second-service", LogFormat: "json"})
+	assert.NoError(t, err)
+	global := GetLevel()
+
+	rec := httptest.NewRecorder()
+	first.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"error"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, LevelError, first.GetLevel())
+	assert.Equal(t, LevelInfo, second.GetLevel())
+	assert.Equal(t, global, GetLevel())
+	assert.False(t, first.SrvcLog.enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, second.SrvcLog.enabled(context.Background(), slog.LevelWarn))
+}
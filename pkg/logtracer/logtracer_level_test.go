@@ -1,7 +1,13 @@
 package logtracer
 
 import (
+	"bytes"
+	"context"
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"log/slog"
+	"os"
 	"testing"
 )
 
@@ -14,6 +20,9 @@ func TestLogLevel(t *testing.T) {
 		{LevelError, "Error"},
 		{LevelWarn, "Warn"},
 		{LevelDebug, "Debug"},
+		{LevelTrace, "Trace"},
+		{LevelFatal, "Fatal"},
+		{LogLevel(42), "LogLevel(42)"},
 	}
 
 	for _, tt := range tests {
@@ -22,3 +31,135 @@ func TestLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestCategoryLevel(t *testing.T) {
+	var buf bytes.Buffer
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})),
+		serviceName: "test-service",
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	grpcCat := lt.RegisterCategory("GRPC")
+	srvcCat := lt.RegisterCategory("SRVC")
+	ctx := context.Background()
+
+	assert.NoError(t, lt.SetCategoryLevel("GRPC", LevelDebug))
+	level, ok := grpcCat.Level()
+	assert.True(t, ok)
+	assert.Equal(t, LevelDebug, level)
+
+	grpcCat.Debug(ctx, "grpc debug")
+	assert.Contains(t, buf.String(), "grpc debug")
+
+	buf.Reset()
+	srvcCat.Debug(ctx, "srvc debug")
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, lt.SetCategoryLevel("SRVC", LevelError))
+	srvcCat.Warn(ctx, "srvc warn")
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, lt.ResetCategoryLevel("GRPC"))
+	_, ok = grpcCat.Level()
+	assert.False(t, ok)
+	grpcCat.Debug(ctx, "grpc debug")
+	assert.Empty(t, buf.String())
+
+	assert.ErrorIs(t, lt.SetCategoryLevel("MISSING", LevelDebug), ErrUnknownCategory)
+	assert.ErrorIs(t, lt.ResetCategoryLevel("MISSING"), ErrUnknownCategory)
+}
+
+func TestLogLevelOrdering(t *testing.T) {
+	ordered := []LogLevel{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+	for i := 1; i < len(ordered); i++ {
+		assert.Less(t, ordered[i-1], ordered[i])
+		assert.Less(t, getLogLevel(ordered[i-1]), getLogLevel(ordered[i]))
+	}
+
+	for _, level := range ordered {
+		SetLevel(level)
+		assert.Equal(t, level, GetLevel())
+	}
+	SetLevel(LevelInfo)
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected LogLevel
+		wantErr  bool
+	}{
+		{"trace", LevelTrace, false},
+		{"DEBUG", LevelDebug, false},
+		{" info ", LevelInfo, false},
+		{"warning", LevelWarn, false},
+		{"Error", LevelError, false},
+		{"fatal", LevelFatal, false},
+		{"verbose", LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestTraceAndFatal(t *testing.T) {
+	var buf bytes.Buffer
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: handlers.LevelTrace})),
+		serviceName: "test-service",
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	cl := lt.RegisterCategory("TEST")
+	ctx := context.Background()
+
+	exitCode := -1
+	exitFunc = func(code int) { exitCode = code }
+	defer func() { exitFunc = os.Exit }()
+
+	cl.Tracef(ctx, "trace %s", "message")
+	assert.Contains(t, buf.String(), "trace message")
+
+	buf.Reset()
+	cl.Fatal(ctx, "fatal message")
+	assert.Contains(t, buf.String(), "fatal message")
+	assert.Equal(t, 1, exitCode)
+
+	exitCode = -1
+	WithoutTracer{cl: cl}.Fatalf(ctx, "fatal %d", 2)
+	assert.Equal(t, 1, exitCode)
+}
+
+// keepOnShutdown keeps the recorded spans readable after Shutdown.
+type keepOnShutdown struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keepOnShutdown) Shutdown(context.Context) error { return nil }
+
+func TestFatalExportsActiveSpan(t *testing.T) {
+	exporter := keepOnShutdown{tracetest.NewInMemoryExporter()}
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, SpanExporter: exporter})
+	assert.NoError(t, err)
+
+	exitCode := -1
+	exitFunc = func(code int) { exitCode = code }
+	defer func() { exitFunc = os.Exit }()
+
+	ctx := lt.StartSpan(context.Background(), "fatal-span")
+	lt.SrvcLog.Fatal(ctx, "fatal message")
+
+	assert.Equal(t, 1, exitCode)
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "fatal-span", spans[0].Name)
+	}
+}
@@ -0,0 +1,224 @@
+package logtracer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"google.golang.org/grpc/credentials"
+	"os"
+	"strings"
+	"time"
+)
+
+// OTLPConfig tunes the otlp-http and otlp-grpc exporters. Zero fields keep the
+// exporter defaults, which honor the standard OTEL_EXPORTER_OTLP_* and
+// OTEL_EXPORTER_OTLP_TRACES_* environment variables; fields set here win over
+// the environment.
+type OTLPConfig struct {
+	// TLS enables TLS towards the collector. Without it, and unless the
+	// endpoint is an https URL or the environment says otherwise, the
+	// exporter connects in plaintext.
+	TLS *TLSConfig
+
+	Headers map[string]string
+	// Compression is "gzip" or "none". The gRPC exporter can only turn gzip
+	// on, so "none" is rejected for otlp-grpc.
+	Compression string
+	// URLPath replaces /v1/traces; otlp-http only.
+	URLPath string
+	Timeout time.Duration
+	Retry   *RetryConfig
+}
+
+// TLSConfig configures TLS for the OTLP exporters. An empty TLSConfig uses the
+// system roots.
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// RetryConfig controls how failed exports are retried with exponential backoff.
+// Zero intervals keep the exporter defaults: 5s initial, 30s maximum and 1m
+// elapsed.
+type RetryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// withDefaults fills the zero intervals, which the exporters would otherwise
+// take literally and retry in a tight loop.
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.InitialInterval == 0 {
+		r.InitialInterval = 5 * time.Second
+	}
+	if r.MaxInterval == 0 {
+		r.MaxInterval = 30 * time.Second
+	}
+	if r.MaxElapsedTime == 0 {
+		r.MaxElapsedTime = time.Minute
+	}
+	return r
+}
+
+// exporterKind resolves the exporter when Config.Exporter is empty, using
+// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL.
+func (cfg Config) exporterKind() string {
+	if cfg.Exporter != "" {
+		return strings.ToLower(cfg.Exporter)
+	}
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if protocol := os.Getenv(env); protocol != "" {
+			if protocol == "grpc" {
+				return ExporterOTLPGRPC
+			}
+			return ExporterOTLPHTTP
+		}
+	}
+	return ExporterOTLPHTTP
+}
+
+// otlpInsecure reports whether the exporter must be forced to plaintext, which
+// was the only mode before TLS could be configured.
+func (cfg Config) otlpInsecure() bool {
+	if cfg.OTLP != nil && cfg.OTLP.TLS != nil {
+		return false
+	}
+	if strings.Contains(cfg.OTLPEndpoint, "://") {
+		// The URL scheme decides.
+		return false
+	}
+	envs := []string{"OTEL_EXPORTER_OTLP_INSECURE", "OTEL_EXPORTER_OTLP_TRACES_INSECURE"}
+	if cfg.OTLPEndpoint == "" {
+		envs = append(envs, "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	for _, env := range envs {
+		if os.Getenv(env) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func otlpHTTPOptions(cfg Config) ([]otlptracehttp.Option, error) {
+	var opts []otlptracehttp.Option
+	if cfg.otlpInsecure() {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if strings.Contains(cfg.OTLPEndpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	} else if cfg.OTLPEndpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+	}
+
+	o := cfg.OTLP
+	if o == nil {
+		return opts, nil
+	}
+	if o.TLS != nil {
+		tlsCfg, err := o.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsCfg))
+	}
+	if len(o.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(o.Headers))
+	}
+	switch strings.ToLower(o.Compression) {
+	case "gzip":
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	case "none":
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.NoCompression))
+	}
+	if o.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(o.URLPath))
+	}
+	if o.Timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(o.Timeout))
+	}
+	if o.Retry != nil {
+		opts = append(opts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig(o.Retry.withDefaults())))
+	}
+	return opts, nil
+}
+
+func otlpGRPCOptions(cfg Config) ([]otlptracegrpc.Option, error) {
+	var opts []otlptracegrpc.Option
+	if cfg.otlpInsecure() {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if strings.Contains(cfg.OTLPEndpoint, "://") {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.OTLPEndpoint))
+	} else if cfg.OTLPEndpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+	}
+
+	o := cfg.OTLP
+	if o == nil {
+		return opts, nil
+	}
+	if o.TLS != nil {
+		tlsCfg, err := o.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+	if len(o.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(o.Headers))
+	}
+	switch compression := strings.ToLower(o.Compression); compression {
+	case "gzip":
+		opts = append(opts, otlptracegrpc.WithCompressor(compression))
+	case "none":
+		// Also reached when OTEL_EXPORTER_OTLP_PROTOCOL selects gRPC.
+		return nil, errGRPCNoCompression(o.Compression)
+	}
+	if o.Timeout > 0 {
+		opts = append(opts, otlptracegrpc.WithTimeout(o.Timeout))
+	}
+	if o.Retry != nil {
+		opts = append(opts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(o.Retry.withDefaults())))
+	}
+	return opts, nil
+}
+
+// errGRPCNoCompression reports "none" for otlp-grpc: otlptracegrpc only
+// accepts "gzip" and handles anything else as an error.
+func errGRPCNoCompression(value string) error {
+	return &ConfigError{Field: "OTLP.Compression", Value: value, Reason: `must be "gzip" for otlp-grpc; leave it empty to keep the exporter default`}
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OTLP CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in OTLP CA file %q", t.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OTLP client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
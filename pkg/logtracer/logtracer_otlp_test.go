@@ -0,0 +1,164 @@
+package logtracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporterOptions(t *testing.T) {
+	type request struct {
+		path     string
+		auth     string
+		encoding string
+		body     []byte
+	}
+	requests := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Encoding"), body}
+	}))
+	defer srv.Close()
+
+	lt, err := New(Config{
+		ServiceName:   "test-service",
+		EnableTracing: true,
+		OTLPEndpoint:  srv.URL,
+		OTLP: &OTLPConfig{
+			Headers:     map[string]string{"Authorization": "Bearer token"},
+			Compression: "gzip",
+			URLPath:     "/custom/traces",
+			Timeout:     time.Second,
+			Retry:       &RetryConfig{Enabled: false},
+		},
+	})
+	assert.NoError(t, err)
+
+	EndSpan(lt.StartSpan(context.Background(), "operation"))
+	assert.NoError(t, lt.Shutdown(context.Background()))
+
+	got := <-requests
+	assert.Equal(t, "/custom/traces", got.path)
+	assert.Equal(t, "Bearer token", got.auth)
+	assert.Equal(t, "gzip", got.encoding)
+	zr, err := gzip.NewReader(bytes.NewReader(got.body))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(zr)
+	assert.Contains(t, string(body), "operation")
+}
+
+func TestRetryConfigDefaults(t *testing.T) {
+	assert.Equal(t, RetryConfig{Enabled: true, InitialInterval: 5 * time.Second, MaxInterval: 30 * time.Second, MaxElapsedTime: time.Minute},
+		RetryConfig{Enabled: true}.withDefaults())
+
+	custom := RetryConfig{Enabled: true, InitialInterval: time.Second, MaxInterval: 2 * time.Second, MaxElapsedTime: 10 * time.Second}
+	assert.Equal(t, custom, custom.withDefaults())
+}
+
+func TestOTLPInsecure(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		env  map[string]string
+		want bool
+	}{
+		{"host:port", Config{OTLPEndpoint: "localhost:4318"}, nil, true},
+		{"no endpoint", Config{}, nil, true},
+		{"https URL", Config{OTLPEndpoint: "https://collector:4318"}, nil, false},
+		{"TLS", Config{OTLPEndpoint: "collector:4318", OTLP: &OTLPConfig{TLS: &TLSConfig{}}}, nil, false},
+		{"env endpoint", Config{}, map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "https://collector:4318"}, false},
+		{"env endpoint with explicit endpoint", Config{OTLPEndpoint: "localhost:4318"}, map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "https://collector:4318"}, true},
+		{"env insecure", Config{OTLPEndpoint: "localhost:4318"}, map[string]string{"OTEL_EXPORTER_OTLP_TRACES_INSECURE": "false"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			assert.Equal(t, tt.want, tt.cfg.otlpInsecure())
+		})
+	}
+}
+
+func TestExporterKindFromEnv(t *testing.T) {
+	assert.Equal(t, ExporterOTLPHTTP, Config{}.exporterKind())
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	assert.Equal(t, ExporterOTLPGRPC, Config{}.exporterKind())
+	assert.Equal(t, ExporterStdout, Config{Exporter: "STDOUT"}.exporterKind())
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+	assert.Equal(t, ExporterOTLPHTTP, Config{}.exporterKind())
+}
+
+func TestOTLPGRPCRejectsNoCompression(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+
+	_, err := New(Config{ServiceName: "test-service", EnableTracing: true, OTLP: &OTLPConfig{Compression: "none"}})
+	var cfgErr *ConfigError
+	assert.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "OTLP.Compression", cfgErr.Field)
+
+	_, err = New(Config{ServiceName: "test-service", EnableTracing: true, OTLP: &OTLPConfig{Compression: "gzip"}})
+	assert.NoError(t, err)
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+
+	tlsCfg, err := (&TLSConfig{CAFile: certFile, CertFile: certFile, KeyFile: keyFile, ServerName: "collector"}).build()
+	assert.NoError(t, err)
+	assert.NotNil(t, tlsCfg.RootCAs)
+	assert.Len(t, tlsCfg.Certificates, 1)
+	assert.Equal(t, "collector", tlsCfg.ServerName)
+
+	_, err = (&TLSConfig{CAFile: keyFile}).build()
+	assert.ErrorContains(t, err, "no certificates found")
+
+	_, err = (&TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}).build()
+	assert.Error(t, err)
+
+	_, err = New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterOTLPGRPC,
+		OTLP: &OTLPConfig{TLS: &TLSConfig{CAFile: certFile, CertFile: certFile, KeyFile: keyFile}}})
+	assert.NoError(t, err)
+}
+
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "collector"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
@@ -0,0 +1,125 @@
+package logtracer
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+	"net/url"
+	"strings"
+)
+
+// Propagator names accepted in Config.Propagators, following OTEL_PROPAGATORS.
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+	PropagatorJaeger       = "jaeger"
+)
+
+var defaultPropagators = []string{PropagatorTraceContext, PropagatorBaggage}
+
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = defaultPropagators
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case PropagatorJaeger:
+			propagators = append(propagators, jaegerPropagator{})
+		default:
+			return nil, fmt.Errorf("unknown propagator %q", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+const (
+	jaegerHeader        = "uber-trace-id"
+	jaegerBaggagePrefix = "uberctx-"
+)
+
+// jaegerPropagator is the contrib Jaeger propagator extended to accept a
+// URL-encoded uber-trace-id, as sent by some Jaeger clients, and to extract
+// uberctx-* headers into the baggage.
+type jaegerPropagator struct {
+	jaeger.Jaeger
+}
+
+func (p jaegerPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	ctx = p.Jaeger.Extract(ctx, unescapedCarrier{carrier})
+
+	bag, found := baggage.FromContext(ctx), false
+	for _, key := range carrier.Keys() {
+		name, ok := strings.CutPrefix(strings.ToLower(key), jaegerBaggagePrefix)
+		if !ok {
+			continue
+		}
+		value, err := url.PathUnescape(carrier.Get(key))
+		if err != nil {
+			continue
+		}
+		member, err := baggage.NewMemberRaw(name, value)
+		if err != nil {
+			continue
+		}
+		if b, err := bag.SetMember(member); err == nil {
+			bag, found = b, true
+		}
+	}
+	if !found {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// unescapedCarrier URL-decodes the uber-trace-id header.
+type unescapedCarrier struct {
+	propagation.TextMapCarrier
+}
+
+func (c unescapedCarrier) Get(key string) string {
+	value := c.TextMapCarrier.Get(key)
+	if key == jaegerHeader {
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			return unescaped
+		}
+	}
+	return value
+}
@@ -0,0 +1,167 @@
+package logtracer
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestB3Propagator(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	tests := []struct {
+		name       string
+		propagator string
+		want       map[string]string
+	}{
+		{
+			name:       "Single header",
+			propagator: PropagatorB3,
+			want:       map[string]string{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+		},
+		{
+			name:       "Multi header",
+			propagator: PropagatorB3Multi,
+			want: map[string]string{
+				"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"x-b3-spanid":  "00f067aa0ba902b7",
+				"x-b3-sampled": "1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPropagator([]string{tt.propagator})
+			assert.NoError(t, err)
+
+			carrier := propagation.MapCarrier{}
+			p.Inject(ctx, carrier)
+			assert.Equal(t, propagation.MapCarrier(tt.want), carrier)
+
+			extracted := trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+			assert.Equal(t, sc.TraceID(), extracted.TraceID())
+			assert.Equal(t, sc.SpanID(), extracted.SpanID())
+			assert.True(t, extracted.IsSampled())
+			assert.True(t, extracted.IsRemote())
+		})
+	}
+
+	p, err := newPropagator([]string{PropagatorB3})
+	assert.NoError(t, err)
+	extract := func(carrier propagation.MapCarrier) trace.SpanContext {
+		return trace.SpanContextFromContext(p.Extract(context.Background(), carrier))
+	}
+
+	t.Run("64-bit trace ID with debug flag", func(t *testing.T) {
+		extracted := extract(propagation.MapCarrier{"b3": "a3ce929d0e0e4736-00f067aa0ba902b7-d"})
+		assert.Equal(t, "0000000000000000a3ce929d0e0e4736", extracted.TraceID().String())
+		assert.True(t, extracted.IsSampled())
+	})
+
+	t.Run("Deny in single header", func(t *testing.T) {
+		extracted := extract(propagation.MapCarrier{"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0"})
+		assert.True(t, extracted.IsValid())
+		assert.False(t, extracted.IsSampled())
+	})
+
+	t.Run("Deny in multi header", func(t *testing.T) {
+		extracted := extract(propagation.MapCarrier{
+			"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+			"x-b3-spanid":  "00f067aa0ba902b7",
+			"x-b3-sampled": "0",
+		})
+		assert.True(t, extracted.IsValid())
+		assert.False(t, extracted.IsSampled())
+	})
+
+	t.Run("Invalid header", func(t *testing.T) {
+		assert.False(t, extract(propagation.MapCarrier{"b3": "invalid"}).IsValid())
+	})
+}
+
+func TestNewPropagator(t *testing.T) {
+	p, err := newPropagator(nil)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, p.Fields())
+
+	p, err = newPropagator([]string{"tracecontext", "B3Multi"})
+	assert.NoError(t, err)
+	assert.Contains(t, p.Fields(), "x-b3-traceid")
+
+	_, err = newPropagator([]string{"xray"})
+	assert.Error(t, err)
+}
+
+func TestJaegerPropagator(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	p, err := newPropagator([]string{PropagatorJaeger})
+	assert.NoError(t, err)
+	extract := func(carrier propagation.MapCarrier) context.Context {
+		return p.Extract(context.Background(), carrier)
+	}
+
+	carrier := propagation.MapCarrier{}
+	p.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1", carrier.Get("uber-trace-id"))
+
+	extracted := trace.SpanContextFromContext(extract(carrier))
+	assert.Equal(t, sc.TraceID(), extracted.TraceID())
+	assert.Equal(t, sc.SpanID(), extracted.SpanID())
+	assert.True(t, extracted.IsSampled())
+
+	t.Run("Short IDs", func(t *testing.T) {
+		extracted := trace.SpanContextFromContext(extract(propagation.MapCarrier{"uber-trace-id": "a3ce929d0e0e4736:f067aa0ba902b7:0:0"}))
+		assert.Equal(t, "0000000000000000a3ce929d0e0e4736", extracted.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", extracted.SpanID().String())
+		assert.False(t, extracted.IsSampled())
+	})
+
+	t.Run("URL encoded", func(t *testing.T) {
+		extracted := trace.SpanContextFromContext(extract(propagation.MapCarrier{"uber-trace-id": "4bf92f3577b34da6a3ce929d0e0e4736%3A00f067aa0ba902b7%3A0%3A1"}))
+		assert.Equal(t, sc.TraceID(), extracted.TraceID())
+		assert.True(t, extracted.IsSampled())
+	})
+
+	t.Run("Debug flag", func(t *testing.T) {
+		ctx := extract(propagation.MapCarrier{"uber-trace-id": "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:3"})
+		assert.True(t, trace.SpanContextFromContext(ctx).IsSampled())
+
+		out := propagation.MapCarrier{}
+		p.Inject(ctx, out)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:3", out.Get("uber-trace-id"))
+	})
+
+	t.Run("Baggage", func(t *testing.T) {
+		bag := baggage.FromContext(extract(propagation.MapCarrier{
+			"uber-trace-id":   "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1",
+			"uberctx-tenant":  "acme",
+			"uberctx-user-id": "a%20b",
+		}))
+		assert.Equal(t, "acme", bag.Member("tenant").Value())
+		assert.Equal(t, "a b", bag.Member("user-id").Value())
+	})
+}
+
+func TestMetadataCarrier(t *testing.T) {
+	md := metadata.Pairs("Traceparent", "value")
+	carrier := metadataCarrier(md)
+
+	assert.Equal(t, "value", carrier.Get("traceparent"))
+	carrier.Set("B3", "other")
+	assert.Equal(t, []string{"other"}, md.Get("b3"))
+	assert.ElementsMatch(t, []string{"traceparent", "b3"}, carrier.Keys())
+}
@@ -0,0 +1,144 @@
+package logtracer
+
+import (
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
+	"regexp"
+)
+
+// DefaultRedactKeys are the attribute names masked when RedactionConfig.DefaultRules is set.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"authorization", "api_key", "apikey", "cpf", "cnpj", "card_number",
+}
+
+var (
+	EmailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	CPFPattern        = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`)
+	CNPJPattern       = regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`)
+	JWTPattern        = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`)
+)
+
+// RedactionConfig masks sensitive data in every attribute written to stdout
+// and to the span events created for each log line. Fields tagged
+// `logtracer:"redact"` are masked even when no rule is configured.
+type RedactionConfig struct {
+	// Keys are attribute names, case-insensitive, whose values are always masked.
+	Keys []string
+	// Patterns mask the matching substrings of string values.
+	Patterns []*regexp.Regexp
+	// DefaultRules adds DefaultRedactKeys and the email, card number (Luhn
+	// checked), CPF and CNPJ (check digits verified) and JWT patterns.
+	DefaultRules bool
+	// Mask replaces redacted values, "[REDACTED]" by default.
+	Mask string
+}
+
+func newRedactor(cfg *RedactionConfig) *handlers.Redactor {
+	if cfg == nil {
+		return handlers.NewRedactor(nil, nil, "")
+	}
+
+	keys := append([]string{}, cfg.Keys...)
+	var rules []handlers.Rule
+	if cfg.DefaultRules {
+		keys = append(keys, DefaultRedactKeys...)
+		rules = append(rules,
+			handlers.Rule{Pattern: JWTPattern},
+			handlers.Rule{Pattern: EmailPattern},
+			handlers.Rule{Pattern: CNPJPattern, Valid: cnpjValid},
+			handlers.Rule{Pattern: CPFPattern, Valid: cpfValid},
+			handlers.Rule{Pattern: CardNumberPattern, Valid: luhnValid},
+		)
+	}
+	for _, p := range cfg.Patterns {
+		rules = append(rules, handlers.Rule{Pattern: p})
+	}
+	return handlers.NewRedactor(keys, rules, cfg.Mask)
+}
+
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// cpfValid checks the two CPF check digits, rejecting numbers made of a single
+// repeated digit.
+func cpfValid(s string) bool {
+	digits := documentDigits(s, 11)
+	if digits == nil {
+		return false
+	}
+
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += digits[i] * (n + 1 - i)
+		}
+		check := sum * 10 % 11 % 10
+		if check != digits[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// cnpjValid checks the two CNPJ check digits, rejecting numbers made of a
+// single repeated digit.
+func cnpjValid(s string) bool {
+	digits := documentDigits(s, 14)
+	if digits == nil {
+		return false
+	}
+
+	for n := 12; n <= 13; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			// Weights run 2..9 from the rightmost digit, then wrap to 2.
+			sum += digits[i] * ((n-1-i)%8 + 2)
+		}
+		check := 0
+		if r := sum % 11; r >= 2 {
+			check = 11 - r
+		}
+		if check != digits[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// documentDigits returns the digits of s when there are exactly n of them and
+// they are not all the same, nil otherwise.
+func documentDigits(s string, n int) []int {
+	digits := make([]int, 0, n)
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+	if len(digits) != n {
+		return nil
+	}
+	for _, d := range digits[1:] {
+		if d != digits[0] {
+			return digits
+		}
+	}
+	return nil
+}
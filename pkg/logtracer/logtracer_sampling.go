@@ -0,0 +1,218 @@
+package logtracer
+
+import (
+	"fmt"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Samplers accepted in SamplingConfig.Sampler. The OTEL_TRACES_SAMPLER names
+// (always_on, always_off, traceidratio, parentbased_always_on,
+// parentbased_always_off, parentbased_traceidratio) are accepted as well.
+const (
+	SamplerAlways      = "always"
+	SamplerNever       = "never"
+	SamplerRatio       = "ratio"
+	SamplerParentRatio = "parent-ratio"
+	SamplerRateLimit   = "rate-limit"
+)
+
+// SamplingConfig chooses which traces are recorded. Without a Sampler the
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG variables are used, and
+// every trace is sampled when they are not set either.
+type SamplingConfig struct {
+	Sampler string
+	// Ratio is the fraction [0, 1] of traces kept by the ratio samplers.
+	Ratio float64
+	// Rate is the number of traces per second kept by the rate-limit sampler.
+	Rate float64
+	// Rules override the sampler for root spans whose name matches, using
+	// the pattern forms of GRPCOptions. The first matching rule wins; child
+	// spans follow the sampler, so they stay with a sampled parent.
+	Rules []SamplingRule
+}
+
+// SamplingRule keeps Ratio of the spans named like SpanName; 0 drops them all.
+type SamplingRule struct {
+	SpanName string
+	Ratio    float64
+}
+
+// newSampler builds the sampler described by cfg, falling back to the
+// environment and then to always sampling. Unusable OTEL_TRACES_SAMPLER or
+// OTEL_TRACES_SAMPLER_ARG values are reported to warn and replaced by the
+// defaults, as the OpenTelemetry SDKs do.
+func newSampler(cfg *SamplingConfig, warn func(msg string, args ...any)) (sdktrace.Sampler, error) {
+	var sc SamplingConfig
+	if cfg != nil {
+		sc = *cfg
+	}
+	if sc.Sampler == "" {
+		sc.fromEnv(warn)
+	}
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+
+	var root sdktrace.Sampler
+	parentBased := false
+	switch strings.ToLower(sc.Sampler) {
+	case "", SamplerAlways, "always_on":
+		root = sdktrace.AlwaysSample()
+	case SamplerNever, "always_off":
+		root = sdktrace.NeverSample()
+	case SamplerRatio, "traceidratio":
+		root = sdktrace.TraceIDRatioBased(sc.Ratio)
+	case SamplerParentRatio, "parentbased_traceidratio":
+		root, parentBased = sdktrace.TraceIDRatioBased(sc.Ratio), true
+	case "parentbased_always_on":
+		root, parentBased = sdktrace.AlwaysSample(), true
+	case "parentbased_always_off":
+		root, parentBased = sdktrace.NeverSample(), true
+	case SamplerRateLimit:
+		root, parentBased = newRateLimitSampler(sc.Rate), true
+	}
+
+	if len(sc.Rules) > 0 {
+		// A child of a root dropped by a rule must not be sampled on its own,
+		// whatever the sampler.
+		root, parentBased = &ruleSampler{rules: sc.Rules, fallback: root}, true
+	}
+	if parentBased {
+		// Children follow their parent; the rules only see root spans.
+		return sdktrace.ParentBased(root), nil
+	}
+	return root, nil
+}
+
+// validate checks the configured sampler without looking at the environment.
+func (sc *SamplingConfig) validate() error {
+	if sc == nil {
+		return nil
+	}
+	switch strings.ToLower(sc.Sampler) {
+	case "", SamplerAlways, "always_on", SamplerNever, "always_off",
+		SamplerRatio, "traceidratio", SamplerParentRatio, "parentbased_traceidratio",
+		"parentbased_always_on", "parentbased_always_off":
+	case SamplerRateLimit:
+		if sc.Rate <= 0 {
+			return fmt.Errorf("rate-limit sampler needs a positive rate, got %v", sc.Rate)
+		}
+	default:
+		return fmt.Errorf("unknown sampler %q", sc.Sampler)
+	}
+
+	if sc.Ratio < 0 || sc.Ratio > 1 {
+		return fmt.Errorf("ratio must be between 0 and 1, got %v", sc.Ratio)
+	}
+	for _, rule := range sc.Rules {
+		if rule.Ratio < 0 || rule.Ratio > 1 {
+			return fmt.Errorf("rule %q: ratio must be between 0 and 1, got %v", rule.SpanName, rule.Ratio)
+		}
+	}
+	return nil
+}
+
+// fromEnv reads OTEL_TRACES_SAMPLER and, for the ratio and rate-limit
+// samplers, OTEL_TRACES_SAMPLER_ARG. An unknown sampler falls back to always
+// sampling, a missing or bad ratio to 1 and a bad rate to always sampling.
+func (sc *SamplingConfig) fromEnv(warn func(msg string, args ...any)) {
+	sampler := os.Getenv("OTEL_TRACES_SAMPLER")
+	arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG")
+
+	switch strings.ToLower(sampler) {
+	case "":
+	case "always_on", "always_off", "parentbased_always_on", "parentbased_always_off",
+		SamplerAlways, SamplerNever:
+		sc.Sampler = sampler
+	case "traceidratio", "parentbased_traceidratio", SamplerRatio, SamplerParentRatio:
+		sc.Sampler, sc.Ratio = sampler, 1
+		if arg == "" {
+			return
+		}
+		ratio, err := strconv.ParseFloat(arg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			warn("Invalid OTEL_TRACES_SAMPLER_ARG, using a ratio of 1", "sampler", sampler, "arg", arg)
+			return
+		}
+		sc.Ratio = ratio
+	case SamplerRateLimit:
+		rate, err := strconv.ParseFloat(arg, 64)
+		if err != nil || rate <= 0 {
+			warn("Invalid OTEL_TRACES_SAMPLER_ARG, sampling every trace", "sampler", sampler, "arg", arg)
+			return
+		}
+		sc.Sampler, sc.Rate = sampler, rate
+	default:
+		warn("Unsupported OTEL_TRACES_SAMPLER, sampling every trace", "sampler", sampler)
+	}
+}
+
+// ruleSampler applies the first matching rule and leaves the rest to fallback.
+// newSampler wraps it in ParentBased, so it only sees root spans.
+type ruleSampler struct {
+	rules    []SamplingRule
+	fallback sdktrace.Sampler
+}
+
+func (s *ruleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, rule := range s.rules {
+		if matchMethod(rule.SpanName, p.Name) {
+			return sdktrace.TraceIDRatioBased(rule.Ratio).ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleSampler) Description() string {
+	return fmt.Sprintf("RuleSampler{rules:%d,fallback:%s}", len(s.rules), s.fallback.Description())
+}
+
+// rateLimitSampler samples up to rate root spans per second using a token
+// bucket holding at most one second worth of tokens.
+type rateLimitSampler struct {
+	rate float64
+	now  func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimitSampler(rate float64) *rateLimitSampler {
+	return &rateLimitSampler{rate: rate, now: time.Now, tokens: max(rate, 1), last: time.Now()}
+}
+
+func (s *rateLimitSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	decision := sdktrace.Drop
+	if s.take() {
+		decision = sdktrace.RecordAndSample
+	}
+	return sdktrace.SamplingResult{
+		Decision:   decision,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+func (s *rateLimitSampler) take() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.tokens = min(s.tokens+now.Sub(s.last).Seconds()*s.rate, max(s.rate, 1))
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+func (s *rateLimitSampler) Description() string {
+	return fmt.Sprintf("RateLimitSampler{%v/s}", s.rate)
+}
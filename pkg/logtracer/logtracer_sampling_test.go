@@ -0,0 +1,193 @@
+package logtracer
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *SamplingConfig
+		env  map[string]string
+		want string
+	}{
+		{"Default", nil, nil, "AlwaysOnSampler"},
+		{"Never", &SamplingConfig{Sampler: SamplerNever}, nil, "AlwaysOffSampler"},
+		{"Ratio", &SamplingConfig{Sampler: SamplerRatio, Ratio: 0.1}, nil, "TraceIDRatioBased{0.1}"},
+		{"Parent ratio", &SamplingConfig{Sampler: SamplerParentRatio, Ratio: 0.5}, nil, "ParentBased{root:TraceIDRatioBased{0.5}"},
+		{"Rate limit", &SamplingConfig{Sampler: SamplerRateLimit, Rate: 10}, nil, "ParentBased{root:RateLimitSampler{10/s}"},
+		{"Env", nil, map[string]string{"OTEL_TRACES_SAMPLER": "parentbased_traceidratio", "OTEL_TRACES_SAMPLER_ARG": "0.25"}, "ParentBased{root:TraceIDRatioBased{0.25}"},
+		// A ratio of 1 is reported by the sdk as AlwaysOnSampler.
+		{"Env ratio without arg", nil, map[string]string{"OTEL_TRACES_SAMPLER": "traceidratio"}, "AlwaysOnSampler"},
+		{"Env rate limit", nil, map[string]string{"OTEL_TRACES_SAMPLER": "rate-limit", "OTEL_TRACES_SAMPLER_ARG": "5"}, "RateLimitSampler{5/s}"},
+		{"Config wins over env", &SamplingConfig{Sampler: SamplerAlways}, map[string]string{"OTEL_TRACES_SAMPLER": "always_off"}, "AlwaysOnSampler"},
+		{"Rules", &SamplingConfig{Rules: []SamplingRule{{SpanName: "/healthz"}}}, nil, "RuleSampler{rules:1,fallback:AlwaysOnSampler}"},
+		{"Parent based rules", &SamplingConfig{Sampler: SamplerParentRatio, Ratio: 0.5, Rules: []SamplingRule{{SpanName: "/healthz"}}}, nil, "ParentBased{root:RuleSampler{rules:1,fallback:TraceIDRatioBased{0.5}}"},
+		{"Env arg ignored", nil, map[string]string{"OTEL_TRACES_SAMPLER": "parentbased_always_off", "OTEL_TRACES_SAMPLER_ARG": "half"}, "ParentBased{root:AlwaysOffSampler,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			sampler, err := newSampler(tt.cfg, func(msg string, args ...any) {
+				t.Errorf("unexpected warning: %s %v", msg, args)
+			})
+			assert.NoError(t, err)
+			assert.Contains(t, sampler.Description(), tt.want)
+		})
+	}
+}
+
+func TestNewSamplerEnvFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"Unsupported sampler", map[string]string{"OTEL_TRACES_SAMPLER": "jaeger_remote"}, "AlwaysOnSampler"},
+		// A ratio of 1 is reported by the sdk as AlwaysOnSampler.
+		{"Bad ratio", map[string]string{"OTEL_TRACES_SAMPLER": "parentbased_traceidratio", "OTEL_TRACES_SAMPLER_ARG": "half"}, "ParentBased{root:AlwaysOnSampler,"},
+		{"Ratio out of range", map[string]string{"OTEL_TRACES_SAMPLER": "traceidratio", "OTEL_TRACES_SAMPLER_ARG": "2"}, "AlwaysOnSampler"},
+		{"Bad rate", map[string]string{"OTEL_TRACES_SAMPLER": "rate-limit", "OTEL_TRACES_SAMPLER_ARG": "fast"}, "AlwaysOnSampler"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var warnings []string
+			sampler, err := newSampler(nil, func(msg string, args ...any) {
+				warnings = append(warnings, msg)
+			})
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(sampler.Description(), tt.want), sampler.Description())
+			assert.Len(t, warnings, 1)
+		})
+	}
+}
+
+func TestNewSamplerErrors(t *testing.T) {
+	for _, cfg := range []*SamplingConfig{
+		{Sampler: "sometimes"},
+		{Sampler: SamplerRatio, Ratio: 1.5},
+		{Sampler: SamplerRateLimit},
+		{Rules: []SamplingRule{{SpanName: "/healthz", Ratio: -1}}},
+	} {
+		_, err := newSampler(cfg, t.Logf)
+		assert.Error(t, err, cfg)
+	}
+
+	var cfgErr *ConfigError
+	assert.True(t, errors.As(Config{ServiceName: "test-service", Sampling: &SamplingConfig{Sampler: "sometimes"}}.Validate(), &cfgErr))
+	assert.Equal(t, "Sampling", cfgErr.Field)
+
+	// The environment is only read when the tracer provider is built.
+	t.Setenv("OTEL_TRACES_SAMPLER", "traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "half")
+	assert.NoError(t, Config{ServiceName: "test-service"}.Validate())
+}
+
+func TestSamplingRules(t *testing.T) {
+	lt, err := New(Config{
+		ServiceName:   "test-service",
+		EnableTracing: true,
+		Exporter:      ExporterMemory,
+		Sampling: &SamplingConfig{
+			Sampler: SamplerAlways,
+			Rules:   []SamplingRule{{SpanName: "/healthz"}, {SpanName: "/grpc.health.v1.Health/"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	for _, name := range []string{"/healthz", "/grpc.health.v1.Health/Check", "/users"} {
+		EndSpan(lt.StartSpan(context.Background(), name))
+	}
+
+	spans := lt.RecordedSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "/users", spans[0].Name)
+}
+
+func TestSamplingRulesOnlyApplyToRootSpans(t *testing.T) {
+	for _, sampler := range []string{SamplerAlways, SamplerParentRatio} {
+		t.Run(sampler, func(t *testing.T) {
+			lt, err := New(Config{
+				ServiceName:   "test-service",
+				EnableTracing: true,
+				Exporter:      ExporterMemory,
+				Sampling: &SamplingConfig{
+					Sampler: sampler,
+					Ratio:   1,
+					Rules:   []SamplingRule{{SpanName: "/healthz"}},
+				},
+			})
+			assert.NoError(t, err)
+
+			ctx := lt.StartSpan(context.Background(), "/users")
+			EndSpan(lt.StartSpan(ctx, "/healthz"))
+			EndSpan(ctx)
+
+			spans := lt.RecordedSpans()
+			assert.Len(t, spans, 2)
+			assert.Equal(t, "/healthz", spans[0].Name)
+			assert.True(t, spans[0].Parent.IsSampled())
+		})
+	}
+}
+
+func TestSamplingRulesDropChildrenOfDroppedRoots(t *testing.T) {
+	for _, sampler := range []string{SamplerAlways, SamplerRatio, SamplerParentRatio} {
+		t.Run(sampler, func(t *testing.T) {
+			lt, err := New(Config{
+				ServiceName:   "test-service",
+				EnableTracing: true,
+				Exporter:      ExporterMemory,
+				Sampling: &SamplingConfig{
+					Sampler: sampler,
+					Ratio:   1,
+					Rules:   []SamplingRule{{SpanName: "/healthz"}},
+				},
+			})
+			assert.NoError(t, err)
+
+			ctx := lt.StartSpan(context.Background(), "/healthz")
+			EndSpan(lt.StartSpan(ctx, "db.ping"))
+			EndSpan(ctx)
+
+			assert.Empty(t, lt.RecordedSpans())
+		})
+	}
+}
+
+func TestRateLimitSampler(t *testing.T) {
+	now := time.Now()
+	s := newRateLimitSampler(2)
+	s.now = func() time.Time { return now }
+	s.last = now
+
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: trace.TraceID{1}, Name: "op"}
+	decisions := func(n int) (kept int) {
+		for i := 0; i < n; i++ {
+			if s.ShouldSample(params).Decision == sdktrace.RecordAndSample {
+				kept++
+			}
+		}
+		return kept
+	}
+
+	assert.Equal(t, 2, decisions(5))
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, 1, decisions(5))
+	now = now.Add(time.Hour)
+	assert.Equal(t, 2, decisions(5))
+}
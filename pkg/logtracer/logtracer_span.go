@@ -3,6 +3,7 @@ package logtracer
 import (
 	"context"
 	"fmt"
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	tracer "go.opentelemetry.io/otel/trace"
@@ -12,17 +13,26 @@ import (
 // type customIDKey struct{}
 
 func StartSpan(ctx context.Context, name string, opts ...SpanOption) context.Context {
+	return startSpan(ctx, globalTracer, customID, name, opts...)
+}
+
+// StartSpan starts a span using the tracer of this LogTracer instance.
+func (lt *LogTracer) StartSpan(ctx context.Context, name string, opts ...SpanOption) context.Context {
+	return startSpan(ctx, lt.tracer, lt.customID, name, opts...)
+}
+
+func startSpan(ctx context.Context, t tracer.Tracer, customID CustomID, name string, opts ...SpanOption) context.Context {
 	options := &SpanOptions{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	if customID != "" {
+	if customID != "" && options.ID != "" {
 		ctx = context.WithValue(ctx, customID.String(), options.ID)
 	}
 
 	var span tracer.Span
-	if globalTracer != nil {
+	if t != nil {
 		var attrs []attribute.KeyValue
 		for k, v := range options.Attributes {
 			attrs = append(attrs, attribute.String(k, v))
@@ -31,7 +41,7 @@ func StartSpan(ctx context.Context, name string, opts ...SpanOption) context.Con
 		if options.ID != "" {
 			attrs = append(attrs, attribute.String("custom.id", options.ID))
 		}
-		ctx, span = globalTracer.Start(ctx, name, tracer.WithAttributes(attrs...))
+		ctx, span = t.Start(ctx, name, tracer.WithAttributes(attrs...))
 	} else {
 		noopTrace := noop.NewTracerProvider()
 		noopNewTracer := noopTrace.Tracer("")
@@ -43,6 +53,10 @@ func StartSpan(ctx context.Context, name string, opts ...SpanOption) context.Con
 
 type spanKey struct{}
 
+// requestIDKey holds the request ID stored by the Gin middleware, which is
+// logged as id when no custom ID is set.
+type requestIDKey struct{}
+
 func AddAttribute(ctx context.Context, key string, value interface{}) {
 	if span, ok := ctx.Value(spanKey{}).(tracer.Span); ok && span.IsRecording() {
 		span.SetAttributes(attribute.String(key, fmt.Sprint(value)))
@@ -56,14 +70,28 @@ func EndSpan(ctx context.Context) {
 }
 
 func GetCustomID(ctx context.Context) string {
-	id, _ := ctx.Value(customID.String()).(string)
+	return getCustomID(ctx, customID)
+}
+
+// GetCustomID returns the custom ID stored in ctx under this instance's key.
+func (lt *LogTracer) GetCustomID(ctx context.Context) string {
+	return getCustomID(ctx, lt.customID)
+}
+
+func getCustomID(ctx context.Context, key CustomID) string {
+	if key == "" {
+		return ""
+	}
+	id, _ := ctx.Value(key.String()).(string)
 	return id
 }
 
-func getOrCreateTraceID(ctx context.Context) string {
-	customID := GetCustomID(ctx)
-	if customID != "" {
-		return customID
+func getOrCreateTraceID(ctx context.Context, key CustomID) string {
+	if id := getCustomID(ctx, key); id != "" {
+		return id
+	}
+	if id, _ := ctx.Value(requestIDKey{}).(string); id != "" {
+		return id
 	}
 
 	spanCtx := tracer.SpanContextFromContext(ctx)
@@ -73,7 +101,7 @@ func getOrCreateTraceID(ctx context.Context) string {
 	return ""
 }
 
-func recordLogSpan(ctx context.Context, level LogLevel, msg string, args ...any) {
+func recordLogSpan(ctx context.Context, level LogLevel, redactor *handlers.Redactor, msg string, args ...any) {
 	span := tracer.SpanFromContext(ctx)
 	if !span.IsRecording() {
 		return
@@ -88,14 +116,34 @@ func recordLogSpan(ctx context.Context, level LogLevel, msg string, args ...any)
 		if i+1 < len(args) {
 			key, ok := args[i].(string)
 			if ok {
-				attrs = append(attrs, attribute.String(key, fmt.Sprint(args[i+1])))
+				attrs = append(attrs, attribute.String(key, fmt.Sprint(redactor.Redact(key, args[i+1]))))
 			}
 		}
 	}
 
 	span.AddEvent("log", tracer.WithAttributes(attrs...))
 
-	if level == LevelError {
+	if level >= LevelError {
+		span.SetAttributes(errorLoggedKey.Bool(true))
 		span.SetStatus(codes.Error, "execution error")
 	}
 }
+
+// recordPanic records a recovered panic as an exception event on the active
+// span and marks it as failed.
+func recordPanic(ctx context.Context, recovered any, stack []byte) {
+	span := tracer.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	err, ok := recovered.(error)
+	if !ok {
+		err = fmt.Errorf("%v", recovered)
+	}
+	span.RecordError(err, tracer.WithAttributes(
+		attribute.String("exception.stacktrace", string(stack)),
+		attribute.Bool("exception.escaped", true),
+	))
+	span.SetStatus(codes.Error, fmt.Sprintf("panic: %v", recovered))
+}
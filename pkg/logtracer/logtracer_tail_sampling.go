@@ -0,0 +1,231 @@
+package logtracer
+
+import (
+	"container/list"
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errorLoggedKey is set on a span when a line at LevelError or above is
+// logged within it.
+const errorLoggedKey = attribute.Key("logtracer.error_logged")
+
+const (
+	defaultTailMaxTraces        = 10000
+	defaultTailMaxSpansPerTrace = 1000
+)
+
+// TailSamplingConfig keeps every trace that failed and KeepRatio of the
+// healthy ones. Spans are buffered per trace until its local root span ends;
+// a trace failed when one of its spans has an Error status or logged at
+// LevelError, or when the root took LatencyThreshold or longer. Use it with
+// the default (always) head sampler so every span reaches the buffer.
+type TailSamplingConfig struct {
+	// KeepRatio is the fraction [0, 1] of healthy traces that are kept.
+	KeepRatio float64
+	// LatencyThreshold keeps traces whose root span is at least this slow.
+	// Zero disables the check.
+	LatencyThreshold time.Duration
+	// MaxTraces bounds the buffered traces (10000 by default). When full, the
+	// oldest trace is decided with the spans it has so far and counted as
+	// evicted.
+	MaxTraces int
+	// MaxSpansPerTrace bounds the spans buffered per trace (1000 by default);
+	// extra spans are dropped and counted. The local root span is always
+	// kept, in place of the last buffered child if needed.
+	MaxSpansPerTrace int
+}
+
+// TailSamplingStats reports what the tail sampler did since it started.
+type TailSamplingStats struct {
+	KeptTraces     uint64
+	DroppedTraces  uint64
+	EvictedTraces  uint64
+	DroppedSpans   uint64
+	BufferedTraces int
+}
+
+type tailTrace struct {
+	id     trace.TraceID
+	spans  []sdktrace.ReadOnlySpan
+	failed bool
+	elem   *list.Element
+}
+
+// tailSamplingProcessor buffers ended spans per trace and forwards the traces
+// it keeps to next.
+type tailSamplingProcessor struct {
+	next    sdktrace.SpanProcessor
+	cfg     TailSamplingConfig
+	healthy sdktrace.Sampler
+
+	mu     sync.Mutex
+	traces map[trace.TraceID]*tailTrace
+	order  *list.List
+	// decided remembers recent decisions for spans ending after their root.
+	decided      map[trace.TraceID]bool
+	decidedOrder *list.List
+
+	kept, dropped, evicted, droppedSpans atomic.Uint64
+}
+
+func newTailSamplingProcessor(cfg TailSamplingConfig, next sdktrace.SpanProcessor) *tailSamplingProcessor {
+	if cfg.MaxTraces <= 0 {
+		cfg.MaxTraces = defaultTailMaxTraces
+	}
+	if cfg.MaxSpansPerTrace <= 0 {
+		cfg.MaxSpansPerTrace = defaultTailMaxSpansPerTrace
+	}
+	return &tailSamplingProcessor{
+		next:         next,
+		cfg:          cfg,
+		healthy:      sdktrace.TraceIDRatioBased(cfg.KeepRatio),
+		traces:       make(map[trace.TraceID]*tailTrace),
+		order:        list.New(),
+		decided:      make(map[trace.TraceID]bool),
+		decidedOrder: list.New(),
+	}
+}
+
+func (p *tailSamplingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *tailSamplingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	var forward []sdktrace.ReadOnlySpan
+
+	p.mu.Lock()
+	id := s.SpanContext().TraceID()
+	if keep, ok := p.decided[id]; ok {
+		if keep {
+			forward = append(forward, s)
+		}
+		p.mu.Unlock()
+		p.forward(forward)
+		return
+	}
+
+	t, ok := p.traces[id]
+	if !ok {
+		if len(p.traces) >= p.cfg.MaxTraces {
+			oldest := p.order.Front().Value.(*tailTrace)
+			p.evicted.Add(1)
+			forward = p.decide(oldest, false)
+		}
+		t = &tailTrace{id: id}
+		t.elem = p.order.PushBack(t)
+		p.traces[id] = t
+	}
+
+	parent := s.Parent()
+	root := !parent.IsValid() || parent.IsRemote()
+	switch {
+	case len(t.spans) < p.cfg.MaxSpansPerTrace:
+		t.spans = append(t.spans, s)
+	case root:
+		// Keep the local root in place of the last buffered child.
+		t.spans[len(t.spans)-1] = s
+		p.droppedSpans.Add(1)
+	default:
+		p.droppedSpans.Add(1)
+	}
+	t.failed = t.failed || spanFailed(s)
+
+	if root {
+		slow := p.cfg.LatencyThreshold > 0 && s.EndTime().Sub(s.StartTime()) >= p.cfg.LatencyThreshold
+		forward = append(forward, p.decide(t, slow)...)
+	}
+	p.mu.Unlock()
+
+	p.forward(forward)
+}
+
+// decide removes t from the buffer and returns its spans if the trace is kept.
+// It must be called with p.mu held.
+func (p *tailSamplingProcessor) decide(t *tailTrace, slow bool) []sdktrace.ReadOnlySpan {
+	p.order.Remove(t.elem)
+	delete(p.traces, t.id)
+
+	keep := t.failed || slow ||
+		p.healthy.ShouldSample(sdktrace.SamplingParameters{TraceID: t.id}).Decision == sdktrace.RecordAndSample
+
+	p.decided[t.id] = keep
+	p.decidedOrder.PushBack(t.id)
+	if p.decidedOrder.Len() > p.cfg.MaxTraces {
+		delete(p.decided, p.decidedOrder.Remove(p.decidedOrder.Front()).(trace.TraceID))
+	}
+
+	if !keep {
+		p.dropped.Add(1)
+		return nil
+	}
+	p.kept.Add(1)
+	return t.spans
+}
+
+func (p *tailSamplingProcessor) forward(spans []sdktrace.ReadOnlySpan) {
+	for _, s := range spans {
+		p.next.OnEnd(s)
+	}
+}
+
+// flushPending decides every buffered trace with the spans it has so far.
+func (p *tailSamplingProcessor) flushPending() {
+	var forward []sdktrace.ReadOnlySpan
+	p.mu.Lock()
+	for p.order.Len() > 0 {
+		forward = append(forward, p.decide(p.order.Front().Value.(*tailTrace), false)...)
+	}
+	p.mu.Unlock()
+	p.forward(forward)
+}
+
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.flushPending()
+	return p.next.Shutdown(ctx)
+}
+
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *tailSamplingProcessor) stats() TailSamplingStats {
+	p.mu.Lock()
+	buffered := len(p.traces)
+	p.mu.Unlock()
+
+	return TailSamplingStats{
+		KeptTraces:     p.kept.Load(),
+		DroppedTraces:  p.dropped.Load(),
+		EvictedTraces:  p.evicted.Load(),
+		DroppedSpans:   p.droppedSpans.Load(),
+		BufferedTraces: buffered,
+	}
+}
+
+func spanFailed(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+	for _, attr := range s.Attributes() {
+		if attr.Key == errorLoggedKey && attr.Value.AsBool() {
+			return true
+		}
+	}
+	return false
+}
+
+// TailSamplingStats returns the counters of the tail sampler configured with
+// Config.TailSampling, or zero stats when it is not enabled.
+func (lt *LogTracer) TailSamplingStats() TailSamplingStats {
+	if lt.tailSampler == nil {
+		return TailSamplingStats{}
+	}
+	return lt.tailSampler.stats()
+}
@@ -0,0 +1,112 @@
+package logtracer
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func newTailSamplingTracer(t *testing.T, cfg TailSamplingConfig) *LogTracer {
+	t.Helper()
+	lt, err := New(Config{ServiceName: "test-service", EnableTracing: true, Exporter: ExporterMemory, TailSampling: &cfg})
+	assert.NoError(t, err)
+	return lt
+}
+
+func TestTailSampling(t *testing.T) {
+	t.Run("Keeps failing traces only", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{KeepRatio: 0})
+
+		root := lt.StartSpan(context.Background(), "healthy")
+		EndSpan(lt.StartSpan(root, "child"))
+		EndSpan(root)
+
+		root = lt.StartSpan(context.Background(), "failing")
+		child := lt.StartSpan(root, "child")
+		lt.SrvcLog.Error(child, "query failed")
+		EndSpan(child)
+		EndSpan(root)
+
+		spans := lt.RecordedSpans()
+		assert.Len(t, spans, 2)
+		for _, s := range spans {
+			assert.Equal(t, spans[0].SpanContext.TraceID(), s.SpanContext.TraceID())
+		}
+		assert.Equal(t, TailSamplingStats{KeptTraces: 1, DroppedTraces: 1}, lt.TailSamplingStats())
+	})
+
+	t.Run("Keeps healthy traces with ratio 1", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{KeepRatio: 1})
+		EndSpan(lt.StartSpan(context.Background(), "healthy"))
+		assert.Len(t, lt.RecordedSpans(), 1)
+	})
+
+	t.Run("Keeps slow traces", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{LatencyThreshold: time.Second})
+		start := time.Now()
+		_, slow := lt.tracer.Start(context.Background(), "slow", trace.WithTimestamp(start))
+		slow.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+		_, fast := lt.tracer.Start(context.Background(), "fast", trace.WithTimestamp(start))
+		fast.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+
+		spans := lt.RecordedSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "slow", spans[0].Name)
+	})
+
+	t.Run("Late spans follow the decision", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{})
+		root := lt.StartSpan(context.Background(), "failing")
+		child := lt.StartSpan(root, "late child")
+		lt.SrvcLog.Error(root, "failed")
+		EndSpan(root)
+		EndSpan(child)
+		assert.Len(t, lt.RecordedSpans(), 2)
+	})
+
+	t.Run("Evicts the oldest trace when full", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{MaxTraces: 1})
+
+		first := lt.StartSpan(context.Background(), "first")
+		child := lt.StartSpan(first, "child")
+		lt.SrvcLog.Error(child, "failed")
+		EndSpan(child)
+
+		second := lt.StartSpan(context.Background(), "second")
+		EndSpan(lt.StartSpan(second, "child"))
+
+		// The failing trace was decided early and kept.
+		assert.Len(t, lt.RecordedSpans(), 1)
+		stats := lt.TailSamplingStats()
+		assert.Equal(t, uint64(1), stats.EvictedTraces)
+		assert.Equal(t, uint64(1), stats.KeptTraces)
+		assert.Equal(t, 1, stats.BufferedTraces)
+
+		assert.NoError(t, lt.Shutdown(context.Background()))
+		assert.Equal(t, 0, lt.TailSamplingStats().BufferedTraces)
+		assert.Equal(t, uint64(1), lt.TailSamplingStats().DroppedTraces)
+	})
+
+	t.Run("Bounds spans per trace", func(t *testing.T) {
+		lt := newTailSamplingTracer(t, TailSamplingConfig{MaxSpansPerTrace: 2})
+		root := lt.StartSpan(context.Background(), "root")
+		for i := 0; i < 3; i++ {
+			EndSpan(lt.StartSpan(root, "child"))
+		}
+		lt.SrvcLog.Error(root, "failed")
+		EndSpan(root)
+
+		spans := lt.RecordedSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[0].Name)
+		assert.Equal(t, "root", spans[1].Name)
+		assert.Equal(t, uint64(2), lt.TailSamplingStats().DroppedSpans)
+	})
+}
+
+func TestTailSamplingConfigValidate(t *testing.T) {
+	err := Config{ServiceName: "test-service", TailSampling: &TailSamplingConfig{KeepRatio: 2}}.Validate()
+	assert.ErrorContains(t, err, "TailSampling.KeepRatio")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"log/slog"
 	"path/filepath"
 	"testing"
@@ -220,3 +221,74 @@ func checkLogOutput(t *testing.T, got, want string, skip ...string) {
 		}
 	}
 }
+
+func TestNew(t *testing.T) {
+	first, err := New(Config{ServiceName: "first-service", LogFormat: "json", CustomID: "firstID"})
+	assert.NoError(t, err)
+	second, err := New(Config{
+		ServiceName:   "second-service",
+		LogFormat:     "text",
+		EnableTracing: true,
+		OTLPEndpoint:  "localhost:4318",
+	})
+	assert.NoError(t, err)
+
+	assert.NotSame(t, first.SrvcLog, second.SrvcLog)
+	assert.NotNil(t, first.propagator)
+	assert.NotNil(t, first.Categories)
+	assert.Nil(t, first.tracer)
+	assert.NotNil(t, second.tracer)
+	assert.Same(t, first, first.SrvcLog.lt)
+
+	ctx := context.WithValue(context.Background(), "firstID", "abc")
+	assert.Equal(t, "abc", first.GetCustomID(ctx))
+	assert.Equal(t, "", second.GetCustomID(ctx))
+
+	ctx = second.StartSpan(ctx, "test-span")
+	first.SrvcLog.Info(ctx, "first instance")
+	second.SrvcLog.Info(ctx, "second instance")
+	first.NoTrace.Info(ctx, "first instance without trace")
+	EndSpan(ctx)
+
+	assert.NoError(t, first.Shutdown(context.Background()))
+	assert.NoError(t, second.Shutdown(context.Background()))
+}
+
+func TestLogTracerUnaryServerInterceptor(t *testing.T) {
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	interceptor := lt.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+}
+
+func TestZeroValueLogTracerBeforeInitLogger(t *testing.T) {
+	previous := std
+	std = nil
+	defer func() { std = previous }()
+
+	lt := &LogTracer{}
+	unary := lt.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	assert.NotPanics(t, func() {
+		resp, err := unary(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+
+	client := lt.UnaryClientInterceptor()
+	assert.NotPanics(t, func() {
+		err := client(context.Background(), "/test.Service/Method", "req", nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return nil
+			})
+		assert.NoError(t, err)
+	})
+}
@@ -2,31 +2,34 @@ package logtracer
 
 import (
 	"context"
-	"fmt"
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"time"
 )
 
-func initTracerProvider(cfg Config) (*sdktrace.TracerProvider, error) {
-	ctx := context.Background()
-
-	exporter, err := otlptracehttp.New(
-		ctx,
-		otlptracehttp.WithEndpoint(cfg.OTLPEndpoint),
-		otlptracehttp.WithInsecure(),
-	)
+// tracing is the tracer provider with the parts of its pipeline that the
+// LogTracer reports on.
+type tracing struct {
+	provider *sdktrace.TracerProvider
+	// exporter is nil for ExporterNone.
+	exporter sdktrace.SpanExporter
+	tail     *tailSamplingProcessor
+	counters *exportCounters
+}
 
+// initTracerProvider builds the tracing pipeline for cfg; warn receives the
+// problems that fall back to a default instead of failing.
+func initTracerProvider(cfg Config, warn func(msg string, args ...any)) (*tracing, error) {
+	sampler, err := newSampler(cfg.Sampling, warn)
 	if err != nil {
-		SrvcLog.Error(ctx, "Failed to create OTLP exporter",
-			"error", err,
-			"endpoint", cfg.OTLPEndpoint,
-		)
-		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		return nil, err
+	}
+	exporter, err := newSpanExporter(context.Background(), cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	resourceAttrs := []attribute.KeyValue{
@@ -40,31 +43,59 @@ func initTracerProvider(cfg Config) (*sdktrace.TracerProvider, error) {
 	resource.WithProcessRuntimeDescription()
 	resource.WithTelemetrySDK()
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+	t := &tracing{exporter: exporter, counters: &exportCounters{}}
+	opts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-	)
+		sdktrace.WithSampler(sampler),
+	}
+	if exporter != nil {
+		var processor sdktrace.SpanProcessor
+		if _, ok := exporter.(*tracetest.InMemoryExporter); ok {
+			// Spans are visible through RecordedSpans as soon as they end.
+			processor = sdktrace.NewSimpleSpanProcessor(&countingExporter{SpanExporter: exporter, counters: t.counters})
+		} else {
+			processor = newBatchProcessor(exporter, cfg.Batch, t.counters)
+		}
+		if cfg.TailSampling != nil {
+			t.tail = newTailSamplingProcessor(*cfg.TailSampling, processor)
+			processor = t.tail
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
+	}
 
-	otel.SetErrorHandler(&errorLogger{})
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagator)
-	return tp, nil
+	t.provider = sdktrace.NewTracerProvider(opts...)
+	return t, nil
 }
 
-type errorLogger struct{}
+type errorLogger struct {
+	log *CategoryLogger
+}
 
 func (e errorLogger) Handle(err error) {
-	SrvcLog.Error(context.Background(), "Trace export failed",
+	e.log.Error(context.Background(), "Trace export failed",
 		"error", err,
 	)
 }
 
 func Shutdown(ctx context.Context) error {
-	if traceProvider != nil {
+	return shutdownProvider(ctx, traceProvider, stopStats)
+}
+
+// Shutdown flushes and stops the tracer provider of this instance.
+func (lt *LogTracer) Shutdown(ctx context.Context) error {
+	return shutdownProvider(ctx, lt.tracerProvider, lt.stopExportStats)
+}
+
+// shutdownProvider stops the export stats logging, when stop is set, and then
+// flushes and stops tp.
+func shutdownProvider(ctx context.Context, tp *sdktrace.TracerProvider, stop func()) error {
+	if stop != nil {
+		stop()
+	}
+	if tp != nil {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
-		return traceProvider.Shutdown(ctx)
+		return tp.Shutdown(ctx)
 	}
 	return nil
 }
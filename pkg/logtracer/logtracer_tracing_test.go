@@ -16,9 +16,11 @@ func TestInitTracerProvider(t *testing.T) {
 		},
 	}
 
-	provider, err := initTracerProvider(cfg)
+	tracing, err := initTracerProvider(cfg, t.Logf)
 	assert.NoError(t, err)
-	assert.NotNil(t, provider)
+	assert.NotNil(t, tracing.provider)
+	assert.NotNil(t, tracing.exporter)
+	assert.Nil(t, tracing.tail)
 }
 
 func TestShutdown(t *testing.T) {
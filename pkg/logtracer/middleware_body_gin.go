@@ -0,0 +1,148 @@
+package logtracer
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxBodySize = 4096
+	truncatedMarker    = "...[truncated]"
+)
+
+var defaultBodyContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"text/",
+}
+
+// bodyCapture keeps up to limit bytes of what passes through it.
+type bodyCapture struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *bodyCapture) write(p []byte) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		b.truncated = true
+		return
+	}
+	b.buf.Write(p)
+}
+
+func (b *bodyCapture) String() string {
+	if b.truncated {
+		return string(trimPartialRune(b.buf.Bytes())) + truncatedMarker
+	}
+	return b.buf.String()
+}
+
+// trimPartialRune drops the trailing bytes of a UTF-8 character cut in half by
+// truncation.
+func trimPartialRune(p []byte) []byte {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				return p[:i]
+			}
+			break
+		}
+	}
+	return p
+}
+
+// requestBodyReader records the request body as the handler reads it, so the
+// body is never consumed on the handler's behalf.
+type requestBodyReader struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (r *requestBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.capture.write(p[:n])
+	}
+	return n, err
+}
+
+type responseBodyWriter struct {
+	gin.ResponseWriter
+	capture *bodyCapture
+}
+
+func (w *responseBodyWriter) Write(p []byte) (int, error) {
+	w.capture.write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseBodyWriter) WriteString(s string) (int, error) {
+	w.capture.write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bodyLogging reports whether body capture is enabled for the matched route.
+func (cfg GinConfig) bodyLogging(route string) bool {
+	if !cfg.LogRequestBody && !cfg.LogResponseBody {
+		return false
+	}
+	if len(cfg.BodyRoutes) == 0 {
+		return true
+	}
+	for _, r := range cfg.BodyRoutes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg GinConfig) bodyContentTypeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	allowed := cfg.BodyContentTypes
+	if len(allowed) == 0 {
+		allowed = defaultBodyContentTypes
+	}
+	for _, prefix := range allowed {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg GinConfig) maxBodySize() int {
+	if cfg.MaxBodySize > 0 {
+		return cfg.MaxBodySize
+	}
+	return defaultMaxBodySize
+}
+
+// captureBodies wraps the request body and response writer of c according to
+// cfg and returns the captures, nil when disabled.
+func (cfg GinConfig) captureBodies(c *gin.Context) (req, resp *bodyCapture) {
+	if !cfg.bodyLogging(c.FullPath()) {
+		return nil, nil
+	}
+
+	if cfg.LogRequestBody && c.Request.Body != nil && cfg.bodyContentTypeAllowed(c.ContentType()) {
+		req = &bodyCapture{limit: cfg.maxBodySize()}
+		c.Request.Body = &requestBodyReader{ReadCloser: c.Request.Body, capture: req}
+	}
+	if cfg.LogResponseBody {
+		resp = &bodyCapture{limit: cfg.maxBodySize()}
+		c.Writer = &responseBodyWriter{ResponseWriter: c.Writer, capture: resp}
+	}
+	return req, resp
+}
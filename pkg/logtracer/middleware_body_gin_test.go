@@ -0,0 +1,163 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGinMiddlewareBodyLogging(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+
+	exporter := useTestTracerProvider(t)
+
+	cfg := DefaultGinConfig()
+	cfg.LogRequestBody = true
+	cfg.LogResponseBody = true
+	cfg.MaxBodySize = 10
+	cfg.BodyRoutes = []string{"/echo/:id"}
+	cfg.BodySpanAttributes = true
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(OTELMiddleware("test-service"))
+	r.Use(lt.GinMiddlewareWithConfig(cfg))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, c.ContentType(), body)
+	}
+	r.POST("/echo/:id", echo)
+	r.POST("/other", echo)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		want        string
+		absent      []string
+	}{
+		{
+			name:        "Small JSON body",
+			path:        "/echo/1",
+			contentType: "application/json",
+			body:        `{"a":1}`,
+			want:        `{"request-body":"{\"a\":1}","response-body":"{\"a\":1}"}`,
+		},
+		{
+			name:        "Truncated text body",
+			path:        "/echo/1",
+			contentType: "text/plain; charset=utf-8",
+			body:        "0123456789abcdef",
+			want:        `{"request-body":"0123456789...[truncated]","response-body":"0123456789...[truncated]"}`,
+		},
+		{
+			name:        "Content type not allowed",
+			path:        "/echo/1",
+			contentType: "application/octet-stream",
+			body:        "binary",
+			absent:      []string{"request-body", "response-body"},
+		},
+		{
+			name:        "Route not enabled",
+			path:        "/other",
+			contentType: "application/json",
+			body:        `{"a":1}`,
+			absent:      []string{"request-body", "response-body"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.body, rec.Body.String())
+			if tt.want != "" {
+				checkLogOutput(t, buf.String(), tt.want)
+			}
+			for _, key := range tt.absent {
+				assert.NotContains(t, buf.String(), key)
+			}
+		})
+	}
+
+	spans := exporter.GetSpans()
+	assert.NotEmpty(t, spans)
+	assert.Contains(t, spans[0].Attributes, attribute.String("http.request.body", `{"a":1}`))
+}
+
+// useTestTracerProvider installs a global tracer provider recording to the
+// returned exporter, as OTELMiddleware reads it, until the test ends.
+func useTestTracerProvider(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = tp.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func TestGinSpanAttributesWithOTELMiddleware(t *testing.T) {
+	exporter := useTestTracerProvider(t)
+	lt := newGinTestTracer(io.Discard)
+	lt.redactor = newRedactor(&RedactionConfig{DefaultRules: true})
+
+	cfg := DefaultGinConfig()
+	cfg.LogRequestBody = true
+	cfg.BodySpanAttributes = true
+	cfg.LogHeaders = []string{"X-Tenant"}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(OTELMiddleware("test-service"))
+	r.Use(lt.GinMiddlewareWithConfig(cfg))
+	r.POST("/users", func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"email":"ana@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes, attribute.String("http.request.body", `{"email":"[REDACTED]"}`))
+	assert.Contains(t, spans[0].Attributes, attribute.StringSlice("http.request.header.x-tenant", []string{"acme"}))
+}
+
+func TestBodyCaptureTruncatesOnRuneBoundary(t *testing.T) {
+	capture := &bodyCapture{limit: 4}
+	capture.write([]byte("ação"))
+	capture.write([]byte("!"))
+	assert.Equal(t, "aç"+truncatedMarker, capture.String())
+
+	capture = &bodyCapture{limit: 4}
+	capture.write([]byte("aç"))
+	capture.write([]byte("ão"))
+	assert.Equal(t, "aç"+truncatedMarker, capture.String())
+
+	capture = &bodyCapture{limit: 3}
+	capture.write([]byte("€"))
+	assert.Equal(t, "€", capture.String())
+}
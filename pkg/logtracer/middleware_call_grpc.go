@@ -0,0 +1,95 @@
+package logtracer
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverCallArgs records who made the call in ctx and with which deadline on
+// its span, following the OpenTelemetry RPC conventions, and returns the
+// same attributes as log arguments.
+func serverCallArgs(ctx context.Context, fullMethod string, md metadata.MD) []any {
+	service, method := splitFullMethod(fullMethod)
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			attrs = append(attrs, attribute.String("network.peer.address", host))
+			if n, err := strconv.Atoi(port); err == nil {
+				attrs = append(attrs, attribute.Int("network.peer.port", n))
+			}
+		} else {
+			attrs = append(attrs, attribute.String("network.peer.address", addr))
+		}
+	}
+	if values := md.Get(":authority"); len(values) > 0 {
+		attrs = append(attrs, attribute.String("server.address", values[0]))
+	}
+	if values := md.Get("user-agent"); len(values) > 0 {
+		attrs = append(attrs, attribute.String("user_agent.original", values[0]))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		attrs = append(attrs, attribute.Int64("logtracer.rpc.deadline_remaining_ms", time.Until(deadline).Milliseconds()))
+	}
+
+	return spanArgs(ctx, attrs...)
+}
+
+// deadlineArgs reports whether a call with a deadline ran out of time.
+func deadlineArgs(ctx context.Context, err error) []any {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil
+	}
+	exceeded := errors.Is(ctx.Err(), context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+	return spanArgs(ctx, attribute.Bool("logtracer.rpc.deadline_exceeded", exceeded))
+}
+
+// messageSizeArgs records the unary request and response as "message" span
+// events carrying their encoded size, like otelgrpc, records the sizes on the
+// span and returns them as log arguments.
+func messageSizeArgs(ctx context.Context, req, resp any) []any {
+	reqSize, respSize := messageSize(req), messageSize(resp)
+	messageEvent(ctx, otelgrpc.RPCMessageTypeReceived, 1, reqSize)
+	if resp != nil {
+		messageEvent(ctx, otelgrpc.RPCMessageTypeSent, 1, respSize)
+	}
+	return spanArgs(ctx,
+		attribute.Int("logtracer.rpc.request_size", reqSize),
+		attribute.Int("logtracer.rpc.response_size", respSize),
+	)
+}
+
+// spanArgs sets attrs on the span in ctx and returns them as log arguments
+// under the same keys.
+func spanArgs(ctx context.Context, attrs ...attribute.KeyValue) []any {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	args := make([]any, 0, 2*len(attrs))
+	for _, kv := range attrs {
+		args = append(args, string(kv.Key), kv.Value.AsInterface())
+	}
+	return args
+}
+
+// splitFullMethod splits "/package.Service/Method" into its service and method.
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "", fullMethod
+	}
+	return service, method
+}
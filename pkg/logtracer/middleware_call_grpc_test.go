@@ -0,0 +1,94 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGRPCServerCallAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	lt, exporter := newStreamTestTracer(t, &buf)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(":authority", "greeter:50051", "user-agent", "grpc-go/1.66.1", "x-tenant", "acme"))
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+	_, err := lt.UnaryServerInterceptor(WithMetadata("x-tenant"))(ctx, wrapperspb.String("world"), info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("hello world"), nil
+	})
+	assert.NoError(t, err)
+
+	checkLogOutput(t, buf.String(), `{"msg":"gRPC request","rpc.system":"grpc","network.peer.address":"10.0.0.7","network.peer.port":51000,"server.address":"greeter:50051","user_agent.original":"grpc-go/1.66.1","logtracer.rpc.request_size":7,"logtracer.rpc.response_size":13,"logtracer.rpc.deadline_exceeded":false,"headers":{"x-tenant":"acme"}}`)
+	assert.Contains(t, buf.String(), `"logtracer.rpc.deadline_remaining_ms":`)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	for _, want := range []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", "helloworld.Greeter"),
+		attribute.String("rpc.method", "SayHello"),
+		attribute.String("network.peer.address", "10.0.0.7"),
+		attribute.Int("network.peer.port", 51000),
+		attribute.String("server.address", "greeter:50051"),
+		attribute.String("user_agent.original", "grpc-go/1.66.1"),
+		attribute.Bool("logtracer.rpc.deadline_exceeded", false),
+		attribute.Int("logtracer.rpc.request_size", 7),
+		attribute.Int("logtracer.rpc.response_size", 13),
+	} {
+		assert.Contains(t, spans[0].Attributes, want)
+	}
+
+	// The request and response are recorded as otelgrpc "message" events.
+	events := spans[0].Events
+	assert.Len(t, events, 3)
+	assert.Equal(t, "message", events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("message.type", "RECEIVED"),
+		attribute.Int64("message.id", 1),
+		attribute.Int64("message.uncompressed_size", 7),
+	}, events[0].Attributes)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("message.type", "SENT"),
+		attribute.Int64("message.id", 1),
+		attribute.Int64("message.uncompressed_size", 13),
+	}, events[1].Attributes)
+}
+
+func TestGRPCServerDeadlineExceeded(t *testing.T) {
+	var buf bytes.Buffer
+	lt, _ := newStreamTestTracer(t, &buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	err := lt.StreamServerInterceptor()(nil, &fakeServerStream{ctx: ctx}, info, func(srv any, stream grpc.ServerStream) error {
+		<-stream.Context().Done()
+		return status.FromContextError(stream.Context().Err()).Err()
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	checkLogOutput(t, buf.String(), `{"msg":"gRPC stream","status":"DeadlineExceeded","logtracer.rpc.deadline_exceeded":true}`)
+}
+
+func TestSplitFullMethod(t *testing.T) {
+	service, method := splitFullMethod("/helloworld.Greeter/SayHello")
+	assert.Equal(t, "helloworld.Greeter", service)
+	assert.Equal(t, "SayHello", method)
+
+	service, method = splitFullMethod("invalid")
+	assert.Equal(t, "", service)
+	assert.Equal(t, "invalid", method)
+}
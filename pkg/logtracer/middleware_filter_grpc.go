@@ -0,0 +1,58 @@
+package logtracer
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"path"
+	"strings"
+)
+
+// matchMethod reports whether the full method matches pattern, see
+// GRPCOptions for the pattern forms.
+func matchMethod(pattern, method string) bool {
+	switch {
+	case pattern == method:
+		return true
+	case strings.HasSuffix(pattern, "/"):
+		return strings.HasPrefix(method, pattern)
+	case strings.ContainsAny(pattern, "*?["):
+		ok, _ := path.Match(pattern, method)
+		return ok
+	}
+	return false
+}
+
+func matchAnyMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *GRPCOptions) allowed(method string) bool {
+	if len(o.AllowMethods) > 0 && !matchAnyMethod(o.AllowMethods, method) {
+		return false
+	}
+	return !matchAnyMethod(o.DenyMethods, method)
+}
+
+func (o *GRPCOptions) logged(method string) bool {
+	return o.allowed(method) && !matchAnyMethod(o.TraceOnlyMethods, method)
+}
+
+func (o *GRPCOptions) traced(method string) bool {
+	return o.allowed(method) && !matchAnyMethod(o.LogOnlyMethods, method)
+}
+
+// startCallSpan starts the span of a call. For methods that are not traced it
+// only installs a non recording span carrying the parent span context, so the
+// trace context is still propagated and the caller's span is left untouched.
+func (lt *LogTracer) startCallSpan(ctx context.Context, options *GRPCOptions, method string) context.Context {
+	if options.traced(method) {
+		return lt.StartSpan(ctx, method)
+	}
+	ctx = trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(ctx))
+	return context.WithValue(ctx, spanKey{}, trace.SpanFromContext(ctx))
+}
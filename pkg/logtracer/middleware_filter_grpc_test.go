@@ -0,0 +1,113 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestMatchMethod(t *testing.T) {
+	tests := []struct {
+		pattern string
+		method  string
+		want    bool
+	}{
+		{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Check", true},
+		{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch", false},
+		{"/grpc.health.v1.Health/", "/grpc.health.v1.Health/Watch", true},
+		{"/grpc.health.v1.Health/", "/grpc.health.v1.HealthX/Watch", false},
+		{"/grpc.reflection.*/*", "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", true},
+		{"/*/Check", "/grpc.health.v1.Health/Check", true},
+		{"/*/Check", "/helloworld.Greeter/SayHello", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.method, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchMethod(tt.pattern, tt.method))
+		})
+	}
+}
+
+func TestGRPCMethodFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	lt, exporter := newStreamTestTracer(t, &buf)
+
+	tests := []struct {
+		name     string
+		opts     []GRPCOption
+		method   string
+		wantLog  bool
+		wantSpan bool
+	}{
+		{"Default", nil, "/grpc.health.v1.Health/Check", true, true},
+		{"Denied", []GRPCOption{WithDenyMethods("/grpc.health.v1.Health/")}, "/grpc.health.v1.Health/Check", false, false},
+		{"Not denied", []GRPCOption{WithDenyMethods("/grpc.health.v1.Health/")}, "/helloworld.Greeter/SayHello", true, true},
+		{"Allowed", []GRPCOption{WithAllowMethods("/helloworld.Greeter/*")}, "/helloworld.Greeter/SayHello", true, true},
+		{"Not allowed", []GRPCOption{WithAllowMethods("/helloworld.Greeter/*")}, "/grpc.health.v1.Health/Check", false, false},
+		{"Trace only", []GRPCOption{WithTraceOnlyMethods("/grpc.health.v1.Health/Check")}, "/grpc.health.v1.Health/Check", false, true},
+		{"Log only", []GRPCOption{WithLogOnlyMethods("/grpc.health.v1.Health/Check")}, "/grpc.health.v1.Health/Check", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			exporter.Reset()
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			called := false
+			_, err := lt.UnaryServerInterceptor(tt.opts...)(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return nil, nil
+			})
+			assert.NoError(t, err)
+			assert.True(t, called)
+			assert.Equal(t, tt.wantLog, buf.Len() > 0)
+			assert.Equal(t, tt.wantSpan, len(exporter.GetSpans()) == 1)
+		})
+	}
+}
+
+func TestGRPCMethodLevel(t *testing.T) {
+	var buf bytes.Buffer
+	lt, _ := newStreamTestTracer(t, &buf)
+	interceptor := lt.UnaryServerInterceptor(WithMethodLevel("/grpc.health.v1.Health/", LevelDebug))
+	info := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+
+	// Successful calls use the method level and are filtered out at Info.
+	_, _ = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Empty(t, buf.String())
+
+	// Failures keep the level of their status code.
+	_, _ = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	checkLogOutput(t, buf.String(), `{"level":"ERROR","msg":"gRPC request","status":"Unavailable"}`)
+}
+
+func TestGRPCClientNotTracedKeepsParent(t *testing.T) {
+	var buf bytes.Buffer
+	lt, exporter := newStreamTestTracer(t, &buf)
+
+	ctx := lt.StartSpan(context.Background(), "parent")
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return status.Error(codes.NotFound, "missing")
+	}
+	err := lt.UnaryClientInterceptor(WithLogOnlyMethods("/test.Service/Method"))(ctx, "/test.Service/Method", "req", nil, nil, invoker)
+	assert.Error(t, err)
+	EndSpan(ctx)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "parent", spans[0].Name)
+	assert.Empty(t, spans[0].Attributes)
+	assert.Len(t, sent.Get("traceparent"), 1)
+	checkLogOutput(t, buf.String(), `{"msg":"gRPC client request","status":"NotFound"}`)
+}
@@ -4,60 +4,292 @@ import (
 	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"math/rand/v2"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// GinConfig configures the Gin logging middleware. Start from
+// DefaultGinConfig, since the zero value logs every request at LevelInfo.
+type GinConfig struct {
+	SuccessLevel     LogLevel
+	ClientErrorLevel LogLevel
+	ServerErrorLevel LogLevel
+
+	// SkipPaths, SkipPathPrefixes and SkipPathRegexps match the request path
+	// (without query) of requests that are never logged, e.g. /healthz.
+	SkipPaths        []string
+	SkipPathPrefixes []string
+	SkipPathRegexps  []*regexp.Regexp
+
+	// SuccessSampleRate is the fraction (0, 1] of requests below 400 that are
+	// logged. Zero logs all of them; errors are always logged.
+	SuccessSampleRate float64
+
+	// RequestIDHeader is read into the request context, logged as id, and
+	// echoed back on the response; with Config.CustomID it is also the
+	// custom ID context value. When the header is missing an ID is generated
+	// with RequestIDGenerator (uuid by default). Empty disables request ID
+	// handling.
+	RequestIDHeader    string
+	RequestIDGenerator func() string
+
+	// LogRequestBody and LogResponseBody add the payloads to the GIN line as
+	// request-body and response-body, limited to MaxBodySize bytes (4096 by
+	// default) and to BodyContentTypes (JSON, form and text by default).
+	// BodyRoutes restricts capture to the listed route templates, e.g.
+	// "/users/:id"; empty enables it on every route. BodySpanAttributes also
+	// sets http.request.body and http.response.body, redacted like the log
+	// line, on the active span.
+	LogRequestBody     bool
+	LogResponseBody    bool
+	MaxBodySize        int
+	BodyContentTypes   []string
+	BodyRoutes         []string
+	BodySpanAttributes bool
+
+	// LogHeaders and LogResponseHeaders list the request and response headers
+	// added to the GIN line under the headers and response-headers groups and
+	// to the span as http.request.header.* and http.response.header.*.
+	// Span attributes need OTELMiddleware registered before this middleware,
+	// so its span is still active when the request is logged.
+	// Authorization, Cookie, Set-Cookie, X-Api-Key and SensitiveHeaders are
+	// always masked.
+	LogHeaders         []string
+	LogResponseHeaders []string
+	SensitiveHeaders   []string
+}
+
+// DefaultGinConfig logs successful requests at Info and 4xx/5xx at Error.
+func DefaultGinConfig() GinConfig {
+	return GinConfig{
+		SuccessLevel:     LevelInfo,
+		ClientErrorLevel: LevelError,
+		ServerErrorLevel: LevelError,
+		RequestIDHeader:  "X-Request-ID",
+	}
+}
+
+func (cfg GinConfig) levelFor(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return cfg.ServerErrorLevel
+	case status >= 400:
+		return cfg.ClientErrorLevel
+	default:
+		return cfg.SuccessLevel
+	}
+}
+
+func (cfg GinConfig) skip(path string) bool {
+	for _, p := range cfg.SkipPaths {
+		if path == p {
+			return true
+		}
+	}
+	for _, prefix := range cfg.SkipPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	for _, re := range cfg.SkipPathRegexps {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg GinConfig) sampled(status int) bool {
+	if status >= 400 || cfg.SuccessSampleRate <= 0 || cfg.SuccessSampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < cfg.SuccessSampleRate
+}
+
+// requestID echoes the request ID back and stores it in the request context,
+// where it is logged as id, and also under key, the custom ID key of the
+// LogTracer owning the GIN logger, when there is one.
+func (cfg GinConfig) requestID(c *gin.Context, key CustomID) {
+	if cfg.RequestIDHeader == "" {
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.GetHeader(cfg.RequestIDHeader)
+	if id == "" && key != "" {
+		id = getCustomID(ctx, key)
+	}
+	if id == "" {
+		if cfg.RequestIDGenerator != nil {
+			id = cfg.RequestIDGenerator()
+		} else {
+			id = uuid.NewString()
+		}
+	}
+
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	if key != "" {
+		ctx = context.WithValue(ctx, key.String(), id)
+	}
+	c.Request = c.Request.WithContext(ctx)
+	c.Header(cfg.RequestIDHeader, id)
+}
+
+// GinMiddleware logs through the default instance, or through a text logger
+// before InitLogger.
 func GinMiddleware(_ string) gin.HandlerFunc {
+	return GinMiddlewareWithConfig(DefaultGinConfig())
+}
+
+// GinMiddlewareWithConfig logs every request through the GIN category of the
+// default instance without touching the global log level.
+func GinMiddlewareWithConfig(cfg GinConfig) gin.HandlerFunc {
+	return ginMiddleware(cfg, defaultOrFallback)
+}
+
+// GinMiddleware logs every request through the GIN category of this instance.
+func (lt *LogTracer) GinMiddleware() gin.HandlerFunc {
+	return lt.GinMiddlewareWithConfig(DefaultGinConfig())
+}
+
+// GinMiddlewareWithConfig logs every request through the GIN category of this
+// instance without touching its log level.
+func (lt *LogTracer) GinMiddlewareWithConfig(cfg GinConfig) gin.HandlerFunc {
+	return ginMiddleware(cfg, lt.orDefault)
+}
+
+// ginMiddleware resolves the owning LogTracer on every request, so the
+// package level middleware follows InitLogger.
+func ginMiddleware(cfg GinConfig, owner func() *LogTracer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		SetLevel(LevelInfo)
-		start := time.Now()
+		lt := owner()
+		cfg.requestID(c, lt.customID)
+
 		path := c.Request.URL.Path
+		if cfg.skip(path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
 		query := c.Request.URL.RawQuery
+		reqBody, respBody := cfg.captureBodies(c)
 
 		c.Next()
 		end := time.Now()
 		latency := end.Sub(start)
 
+		status := c.Writer.Status()
+		if !cfg.sampled(status) {
+			return
+		}
+
 		if query != "" {
 			path = fmt.Sprintf("%s?%s", path, query)
 		}
 
-		logHTTPRequest(
-			c.Request.Context(),
-			c.Writer.Status(),
-			c.Request.Method,
-			path,
-			c.ClientIP(),
-			latency,
-			c.Request.UserAgent(),
-		)
-	}
-}
-
-func logHTTPRequest(ctx context.Context, status int, method, path, ip string, latency time.Duration, userAgent string) {
-	latencyStr := formatLatency(latency)
-	if status >= 400 {
-		ginLog.Error(ctx,
-			"HTTP request",
-			"status", status,
-			"method", method,
-			"path", path,
-			"ip", ip,
-			"latency", latencyStr,
-			"user-agent", userAgent,
-		)
-	} else {
-		ginLog.Info(ctx,
-			"HTTP request",
-			"status", status,
-			"method", method,
-			"path", path,
-			"ip", ip,
-			"latency", latencyStr,
-			"user-agent", userAgent,
-		)
+		ctx := c.Request.Context()
+		req := httpRequest{
+			status:       status,
+			method:       c.Request.Method,
+			path:         path,
+			route:        c.FullPath(),
+			ip:           c.ClientIP(),
+			latency:      latency,
+			userAgent:    c.Request.UserAgent(),
+			protocol:     c.Request.Proto,
+			requestSize:  c.Request.ContentLength,
+			responseSize: c.Writer.Size(),
+			errors:       c.Errors.String(),
+		}
+		if reqBody != nil {
+			req.requestBody = reqBody.String()
+		}
+		if respBody != nil && cfg.bodyContentTypeAllowed(c.Writer.Header().Get("Content-Type")) {
+			req.responseBody = respBody.String()
+		}
+		if cfg.BodySpanAttributes {
+			setBodySpanAttributes(ctx, lt.redactor, req)
+		}
+		req.requestHeaders = captureHeaders(c.Request.Header.Values, cfg.LogHeaders, cfg.SensitiveHeaders)
+		req.responseHeaders = captureHeaders(c.Writer.Header().Values, cfg.LogResponseHeaders, cfg.SensitiveHeaders)
+		setHeaderSpanAttributes(ctx, "http.request.header", req.requestHeaders)
+		setHeaderSpanAttributes(ctx, "http.response.header", req.responseHeaders)
+
+		logHTTPRequest(ctx, lt.ginLog, cfg.levelFor(status), req)
+	}
+}
+
+// setBodySpanAttributes records the bodies on the span with the redaction the
+// log line gets, keyed like the log attributes.
+func setBodySpanAttributes(ctx context.Context, redactor *handlers.Redactor, req httpRequest) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	if req.requestBody != "" {
+		span.SetAttributes(attribute.String("http.request.body", fmt.Sprint(redactor.Redact("request-body", req.requestBody))))
+	}
+	if req.responseBody != "" {
+		span.SetAttributes(attribute.String("http.response.body", fmt.Sprint(redactor.Redact("response-body", req.responseBody))))
+	}
+}
+
+type httpRequest struct {
+	status       int
+	method       string
+	path         string
+	route        string
+	ip           string
+	latency      time.Duration
+	userAgent    string
+	protocol     string
+	requestSize  int64
+	responseSize int
+	errors       string
+	requestBody  string
+	responseBody string
+
+	requestHeaders  []capturedHeader
+	responseHeaders []capturedHeader
+}
+
+func logHTTPRequest(ctx context.Context, log *CategoryLogger, level LogLevel, req httpRequest) {
+	args := []any{
+		"status", req.status,
+		"method", req.method,
+		"path", req.path,
+		"route", req.route,
+		"ip", req.ip,
+		"latency", formatLatency(req.latency),
+		"user-agent", req.userAgent,
+		"protocol", req.protocol,
+		"request-size", max(req.requestSize, 0),
+		"response-size", max(req.responseSize, 0),
+	}
+	if req.errors != "" {
+		args = append(args, "errors", req.errors)
+	}
+	if req.requestBody != "" {
+		args = append(args, "request-body", req.requestBody)
+	}
+	if req.responseBody != "" {
+		args = append(args, "response-body", req.responseBody)
+	}
+	if len(req.requestHeaders) > 0 {
+		args = append(args, headersGroup("headers", req.requestHeaders))
+	}
+	if len(req.responseHeaders) > 0 {
+		args = append(args, headersGroup("response-headers", req.responseHeaders))
 	}
+	log.Log(ctx, level, "HTTP request", args...)
 }
 
 func formatLatency(d time.Duration) string {
@@ -3,10 +3,17 @@ package logtracer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
@@ -19,6 +26,29 @@ func TestGinMiddleware(t *testing.T) {
 	assert.NotNil(t, otelMiddleware)
 }
 
+func TestGinMiddlewareKeepsGlobalLevel(t *testing.T) {
+	assert.NoError(t, InitLogger(Config{ServiceName: "test-service", LogFormat: "json"}))
+	SetLevel(LevelDebug)
+	defer SetLevel(LevelInfo)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinMiddleware("test-service"))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, LevelDebug, GetLevel())
+}
+
+func TestGinConfigLevelFor(t *testing.T) {
+	cfg := GinConfig{SuccessLevel: LevelDebug, ClientErrorLevel: LevelWarn, ServerErrorLevel: LevelError}
+
+	assert.Equal(t, LevelDebug, cfg.levelFor(200))
+	assert.Equal(t, LevelDebug, cfg.levelFor(302))
+	assert.Equal(t, LevelWarn, cfg.levelFor(404))
+	assert.Equal(t, LevelError, cfg.levelFor(503))
+}
+
 func TestLogHTTPRequest(t *testing.T) {
 	var buf bytes.Buffer
 	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
@@ -35,7 +65,7 @@ func TestLogHTTPRequest(t *testing.T) {
 		},
 	}))
 
-	ginLog = newCategoryLogger(l, "test-service", "GIN")
+	log := newCategoryLogger(l, "test-service", "GIN")
 
 	tests := []struct {
 		name      string
@@ -82,7 +112,14 @@ func TestLogHTTPRequest(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			buf.Reset()
-			logHTTPRequest(context.Background(), tt.status, tt.method, tt.path, tt.ip, tt.latency, tt.userAgent)
+			logHTTPRequest(context.Background(), log, DefaultGinConfig().levelFor(tt.status), httpRequest{
+				status:    tt.status,
+				method:    tt.method,
+				path:      tt.path,
+				ip:        tt.ip,
+				latency:   tt.latency,
+				userAgent: tt.userAgent,
+			})
 			if buf.Len() == 0 {
 				t.Fatal("No log output captured")
 			}
@@ -90,3 +127,203 @@ func TestLogHTTPRequest(t *testing.T) {
 		})
 	}
 }
+
+// newGinTestTracer returns a LogTracer without tracing that logs JSON to w.
+func newGinTestTracer(w io.Writer) *LogTracer {
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(w, nil)),
+		serviceName: "test-service",
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.ginLog = lt.RegisterCategory("GIN")
+	return lt
+}
+
+func TestGinMiddlewareWithConfig(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+
+	cfg := DefaultGinConfig()
+	cfg.SkipPaths = []string{"/healthz"}
+	cfg.SkipPathPrefixes = []string{"/metrics"}
+	cfg.SkipPathRegexps = []*regexp.Regexp{regexp.MustCompile(`^/internal/.+/status$`)}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinMiddlewareWithConfig(cfg))
+	r.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/metrics/go", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/internal/:id/status", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/users/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("validation failed"))
+		c.String(http.StatusBadRequest, "bad")
+	})
+
+	for _, path := range []string{"/healthz", "/metrics/go", "/internal/a/status"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Empty(t, buf.String())
+
+	req := httptest.NewRequest(http.MethodPost, "/users/42?debug=1", strings.NewReader(`{"name":"x"}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	checkLogOutput(t, buf.String(), `{"level":"ERROR","msg":"HTTP request","category":"GIN","status":400,"method":"POST","path":"/users/42?debug=1","route":"/users/:id","protocol":"HTTP/1.1","request-size":12,"response-size":3,"errors":"Error #01: validation failed\n"}`)
+}
+
+func TestGinConfigSampling(t *testing.T) {
+	cfg := GinConfig{SuccessSampleRate: 0.000001}
+
+	assert.True(t, cfg.sampled(500))
+	assert.True(t, cfg.sampled(404))
+
+	sampled := 0
+	for i := 0; i < 100; i++ {
+		if cfg.sampled(200) {
+			sampled++
+		}
+	}
+	assert.Less(t, sampled, 5)
+	assert.True(t, GinConfig{}.sampled(200))
+}
+
+func TestGinMiddlewareRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+	lt.customID = "requestID"
+	handlerLog := lt.RegisterCategory("SRVC")
+
+	cfg := DefaultGinConfig()
+	cfg.RequestIDGenerator = func() string { return "generated-id" }
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinMiddlewareWithConfig(cfg))
+	r.GET("/ping", func(c *gin.Context) {
+		ctx := StartSpan(c.Request.Context(), "ping")
+		defer EndSpan(ctx)
+		handlerLog.Info(ctx, "handling ping")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-ID", "incoming-id")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, "incoming-id", rec.Header().Get("X-Request-ID"))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	checkLogOutput(t, lines[0], `{"msg":"handling ping","category":"SRVC","id":"incoming-id"}`)
+	checkLogOutput(t, lines[1], `{"msg":"HTTP request","category":"GIN","id":"incoming-id"}`)
+
+	buf.Reset()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, "generated-id", rec.Header().Get("X-Request-ID"))
+	assert.Contains(t, buf.String(), `"id":"generated-id"`)
+}
+
+func TestGinMiddlewareRequestIDWithoutCustomID(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+	handlerLog := lt.RegisterCategory("SRVC")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinMiddlewareWithConfig(DefaultGinConfig()))
+	r.GET("/ping", func(c *gin.Context) {
+		handlerLog.Info(c.Request.Context(), "handling ping")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-ID", "incoming-id")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	checkLogOutput(t, lines[0], `{"msg":"handling ping","id":"incoming-id"}`)
+	checkLogOutput(t, lines[1], `{"msg":"HTTP request","id":"incoming-id"}`)
+}
+
+func TestGinMiddlewareHeaders(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+
+	cfg := DefaultGinConfig()
+	cfg.LogHeaders = []string{"X-Tenant", "Authorization", "Cookie"}
+	cfg.LogResponseHeaders = []string{"Set-Cookie"}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinMiddlewareWithConfig(cfg))
+	r.GET("/login", func(c *gin.Context) {
+		c.SetCookie("session", "abc", 60, "/", "", false, true)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=old")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "session=")
+	checkLogOutput(t, buf.String(), `{"msg":"HTTP request","headers":{"x-tenant":"acme","authorization":"[REDACTED]","cookie":"[REDACTED]"},"response-headers":{"set-cookie":"[REDACTED]"}}`)
+}
+
+func TestGinMiddlewareRequestIDUsesOwnerKey(t *testing.T) {
+	previous := customID
+	customID = "otherKey"
+	defer func() { customID = previous }()
+
+	var stored, other, empty any
+	serve := func(lt *LogTracer, req *http.Request) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.Use(lt.GinMiddlewareWithConfig(DefaultGinConfig()))
+		r.GET("/ping", func(c *gin.Context) {
+			stored = c.Request.Context().Value("traceKey")
+			other = c.Request.Context().Value("otherKey")
+			empty = c.Request.Context().Value("")
+			c.Status(http.StatusOK)
+		})
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json", CustomID: "traceKey"})
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Request-ID", "incoming-id")
+	serve(lt, req)
+	assert.Equal(t, "incoming-id", stored)
+	assert.Nil(t, other)
+
+	lt, err = New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+	rec := serve(lt, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.NotEmpty(t, rec.Header().Get("X-Request-ID"))
+	assert.Nil(t, stored)
+	assert.Nil(t, empty)
+}
+
+func TestGinMiddlewareWithoutInitLogger(t *testing.T) {
+	previous := std
+	std = nil
+	defer func() { std = previous }()
+
+	lt, err := New(Config{ServiceName: "test-service", LogFormat: "json"})
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	for _, middleware := range []gin.HandlerFunc{lt.GinMiddleware(), GinMiddleware("test-service"), GinRecovery(), lt.GinRecovery()} {
+		r := gin.New()
+		r.Use(middleware)
+		r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+		rec := httptest.NewRecorder()
+		assert.NotPanics(t, func() { r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ping", nil)) })
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
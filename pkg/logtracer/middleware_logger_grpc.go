@@ -3,153 +3,346 @@ package logtracer
 import (
 	"context"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"time"
 )
 
-func extractB3Headers(ctx context.Context) map[string]string {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
+type GRPCOption func(*GRPCOptions)
+
+type GRPCOptions struct {
+	// CodeLevels overrides the level used to log calls ending with a given
+	// status code, see defaultCodeLevels.
+	CodeLevels map[codes.Code]LogLevel
+
+	// CustomIDMetadataKey carries the custom ID (see Config.CustomID) between
+	// client and server interceptors. Defaults to x-request-id.
+	CustomIDMetadataKey string
+
+	// Metadata lists the metadata keys added to the GRPC line under the
+	// headers group and to the span as rpc.grpc.request.metadata.*.
+	// Authorization, Cookie, X-Api-Key and SensitiveMetadata are always masked.
+	Metadata          []string
+	SensitiveMetadata []string
+
+	// MessageEvents adds a "message" span event for every message sent or
+	// received on a stream.
+	MessageEvents bool
+
+	// Method patterns are either an exact full method
+	// ("/grpc.health.v1.Health/Check"), a service prefix ending with a slash
+	// ("/grpc.health.v1.Health/") or a path.Match glob ("/*.Health/*").
+	//
+	// Calls to methods outside AllowMethods (when set) or in DenyMethods are
+	// neither logged nor traced; their trace context is still propagated.
+	// TraceOnlyMethods are traced but not logged and LogOnlyMethods are logged
+	// but not traced. MethodLevels sets the level of successful calls to the
+	// first matching method; failed calls keep their CodeLevels level.
+	AllowMethods     []string
+	DenyMethods      []string
+	TraceOnlyMethods []string
+	LogOnlyMethods   []string
+	MethodLevels     []MethodLevel
+}
+
+// MethodLevel logs successful calls to methods matching Pattern at Level.
+type MethodLevel struct {
+	Pattern string
+	Level   LogLevel
+}
+
+// WithCodeLevel logs calls ending with code at level.
+func WithCodeLevel(code codes.Code, level LogLevel) GRPCOption {
+	return func(o *GRPCOptions) {
+		if o.CodeLevels == nil {
+			o.CodeLevels = make(map[codes.Code]LogLevel)
+		}
+		o.CodeLevels[code] = level
+	}
+}
+
+// WithMetadata logs the given metadata keys of each call.
+func WithMetadata(keys ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.Metadata = append(o.Metadata, keys...)
+	}
+}
+
+// WithSensitiveMetadata masks the values of the given metadata keys.
+func WithSensitiveMetadata(keys ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.SensitiveMetadata = append(o.SensitiveMetadata, keys...)
+	}
+}
+
+// WithCustomIDMetadataKey changes the metadata key used to carry the custom ID.
+func WithCustomIDMetadataKey(key string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.CustomIDMetadataKey = key
+	}
+}
+
+// WithMessageEvents records each stream message as a span event.
+func WithMessageEvents() GRPCOption {
+	return func(o *GRPCOptions) {
+		o.MessageEvents = true
+	}
+}
+
+// WithAllowMethods only logs and traces calls to the matching methods.
+func WithAllowMethods(patterns ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.AllowMethods = append(o.AllowMethods, patterns...)
+	}
+}
+
+// WithDenyMethods neither logs nor traces calls to the matching methods.
+func WithDenyMethods(patterns ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.DenyMethods = append(o.DenyMethods, patterns...)
+	}
+}
+
+// WithTraceOnlyMethods traces calls to the matching methods without logging them.
+func WithTraceOnlyMethods(patterns ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.TraceOnlyMethods = append(o.TraceOnlyMethods, patterns...)
+	}
+}
+
+// WithLogOnlyMethods logs calls to the matching methods without tracing them.
+func WithLogOnlyMethods(patterns ...string) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.LogOnlyMethods = append(o.LogOnlyMethods, patterns...)
+	}
+}
+
+// WithMethodLevel logs successful calls to the matching methods at level.
+func WithMethodLevel(pattern string, level LogLevel) GRPCOption {
+	return func(o *GRPCOptions) {
+		o.MethodLevels = append(o.MethodLevels, MethodLevel{Pattern: pattern, Level: level})
+	}
+}
+
+func newGRPCOptions(opts []GRPCOption) *GRPCOptions {
+	options := &GRPCOptions{CustomIDMetadataKey: "x-request-id"}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// metadataArgs records the configured metadata on the span in ctx and returns
+// the matching log arguments.
+func (o *GRPCOptions) metadataArgs(ctx context.Context, md metadata.MD) []any {
+	headers := captureHeaders(md.Get, o.Metadata, o.SensitiveMetadata)
+	if len(headers) == 0 {
 		return nil
 	}
+	setHeaderSpanAttributes(ctx, "rpc.grpc.request.metadata", headers)
+	return []any{headersGroup("headers", headers)}
+}
+
+// defaultCodeLevels treats caller mistakes as warnings and server faults as
+// errors. Codes missing from the map are logged at LevelError.
+var defaultCodeLevels = map[codes.Code]LogLevel{
+	codes.OK:                 LevelInfo,
+	codes.Canceled:           LevelWarn,
+	codes.InvalidArgument:    LevelWarn,
+	codes.NotFound:           LevelWarn,
+	codes.AlreadyExists:      LevelWarn,
+	codes.PermissionDenied:   LevelWarn,
+	codes.Unauthenticated:    LevelWarn,
+	codes.FailedPrecondition: LevelWarn,
+	codes.OutOfRange:         LevelWarn,
+	codes.ResourceExhausted:  LevelWarn,
+	codes.Aborted:            LevelWarn,
+	codes.Unknown:            LevelError,
+	codes.DeadlineExceeded:   LevelError,
+	codes.Unimplemented:      LevelError,
+	codes.Internal:           LevelError,
+	codes.Unavailable:        LevelError,
+	codes.DataLoss:           LevelError,
+}
+
+func (o *GRPCOptions) levelFor(method string, code codes.Code) LogLevel {
+	if code == codes.OK {
+		for _, ml := range o.MethodLevels {
+			if matchMethod(ml.Pattern, method) {
+				return ml.Level
+			}
+		}
+	}
+	if level, ok := o.CodeLevels[code]; ok {
+		return level
+	}
+	if level, ok := defaultCodeLevels[code]; ok {
+		return level
+	}
+	return LevelError
+}
+
+// logGRPCCall records the final status of a call on the span in ctx and logs
+// it at the level mapped to its status code.
+func (lt *LogTracer) logGRPCCall(ctx context.Context, options *GRPCOptions, msg, method string, duration time.Duration, err error, extra []any) {
+	st, _ := status.FromError(err)
+	code := st.Code()
 
-	headers := make(map[string]string)
-	b3Headers := []string{"x-b3-traceid", "x-b3-spanid", "x-b3-parentspanid", "x-b3-sampled", "x-b3-flags"}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
 
-	for _, header := range b3Headers {
-		if values := md.Get(header); len(values) > 0 {
-			headers[header] = values[0]
+	if options.logged(method) {
+		args := []any{
+			"method", method,
+			"duration", duration,
+			"status", code.String(),
+			"code", int(code),
+		}
+		if code != codes.OK {
+			args = append(args, "error", st.Message())
 		}
+		lt.grpcLog.Log(ctx, options.levelFor(method, code), msg, append(args, extra...)...)
+	}
+
+	// Set after logging, which marks the span with a generic error status.
+	if code != codes.OK {
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+}
+
+// injectOutgoing writes the span context in ctx, the baggage and the custom ID
+// into the outgoing metadata using the configured propagators.
+func (lt *LogTracer) injectOutgoing(ctx context.Context, options *GRPCOptions) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
 	}
 
-	return headers
+	lt.propagator.Inject(ctx, metadataCarrier(md))
+	if id := lt.GetCustomID(ctx); id != "" {
+		md.Set(options.CustomIDMetadataKey, id)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
 }
 
-func (lt *LogTracer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+// incomingCustomID stores the custom ID sent by a client interceptor in ctx,
+// unless ctx already carries one. Nothing is stored without a custom ID key.
+func (lt *LogTracer) incomingCustomID(ctx context.Context, md metadata.MD, options *GRPCOptions) context.Context {
+	if lt.customID == "" || lt.GetCustomID(ctx) != "" {
+		return ctx
+	}
+	if values := md.Get(options.CustomIDMetadataKey); len(values) > 0 && values[0] != "" {
+		return context.WithValue(ctx, lt.customID.String(), values[0])
+	}
+	return ctx
+}
+
+func (lt *LogTracer) UnaryServerInterceptor(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	options := newGRPCOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		lt := lt.orDefault()
 		startTime := time.Now()
 
-		b3Headers := extractB3Headers(ctx)
-		newCtx := lt.propagator.Extract(ctx, propagation.MapCarrier(b3Headers))
-		newCtx = StartSpan(newCtx, info.FullMethod)
+		md, _ := metadata.FromIncomingContext(ctx)
+		newCtx := lt.propagator.Extract(ctx, metadataCarrier(md))
+		newCtx = lt.incomingCustomID(newCtx, md, options)
+		newCtx = lt.startCallSpan(newCtx, options, info.FullMethod)
 		defer EndSpan(newCtx)
 
-		AddAttribute(newCtx, "grpc.Method", info.FullMethod)
+		AddAttribute(newCtx, "grpc.method", info.FullMethod)
+		args := append(serverCallArgs(newCtx, info.FullMethod, md), options.metadataArgs(newCtx, md)...)
 
 		resp, err := handler(newCtx, req)
 
-		duration := time.Since(startTime)
-		statusCode := "OK"
-		if err != nil {
-			statusCode = "ERROR"
-		}
-
-		grpcLog.Info(newCtx,
-			"gRPC request",
-			"method", info.FullMethod,
-			"duration", duration,
-			"status", statusCode,
-		)
+		args = append(args, messageSizeArgs(newCtx, req, resp)...)
+		args = append(args, deadlineArgs(newCtx, err)...)
+		lt.logGRPCCall(newCtx, options, "gRPC request", info.FullMethod, time.Since(startTime), err, args)
 		return resp, err
 	}
 }
 
-func (lt *LogTracer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+func (lt *LogTracer) StreamServerInterceptor(opts ...GRPCOption) grpc.StreamServerInterceptor {
+	options := newGRPCOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		lt := lt.orDefault()
 		startTime := time.Now()
 
-		b3Headers := extractB3Headers(ss.Context())
-		newCtx := lt.propagator.Extract(ss.Context(), propagation.MapCarrier(b3Headers))
-		newCtx = StartSpan(newCtx, info.FullMethod)
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		newCtx := lt.propagator.Extract(ss.Context(), metadataCarrier(md))
+		newCtx = lt.incomingCustomID(newCtx, md, options)
+		newCtx = lt.startCallSpan(newCtx, options, info.FullMethod)
 		defer EndSpan(newCtx)
 
 		AddAttribute(newCtx, "grpc.method", info.FullMethod)
+		args := append(serverCallArgs(newCtx, info.FullMethod, md), options.metadataArgs(newCtx, md)...)
 
-		wrapped := &wrappedServerStream{ServerStream: ss, ctx: newCtx}
-		err := handler(srv, wrapped)
-
-		duration := time.Since(startTime)
-		statusCode := "OK"
-		if err != nil {
-			statusCode = "ERROR"
-		}
+		stats := newStreamStats(newCtx, options.MessageEvents)
+		err := handler(srv, &wrappedServerStream{ServerStream: ss, ctx: newCtx, stats: stats})
 
-		grpcLog.Info(newCtx,
-			"gRPC stream",
-			"method", info.FullMethod,
-			"duration", duration,
-			"status", statusCode,
-		)
+		args = append(args, stats.args()...)
+		args = append(args, deadlineArgs(newCtx, err)...)
+		lt.logGRPCCall(newCtx, options, "gRPC stream", info.FullMethod, time.Since(startTime), err, args)
 
 		return err
 	}
 }
 
-type wrappedServerStream struct {
-	grpc.ServerStream
-	ctx context.Context
-}
-
-func (w *wrappedServerStream) Context() context.Context {
-	return w.ctx
-}
-
-func (lt *LogTracer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+func (lt *LogTracer) UnaryClientInterceptor(opts ...GRPCOption) grpc.UnaryClientInterceptor {
+	options := newGRPCOptions(opts)
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		lt := lt.orDefault()
 		startTime := time.Now()
 
-		newCtx := StartSpan(ctx, method)
+		newCtx := lt.startCallSpan(ctx, options, method)
 		defer EndSpan(newCtx)
 
 		AddAttribute(newCtx, "grpc.method", method)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		mdArgs := options.metadataArgs(newCtx, md)
+		newCtx = lt.injectOutgoing(newCtx, options)
 
 		err := invoker(newCtx, method, req, reply, cc, opts...)
 
-		duration := time.Since(startTime)
-		statusCode := "OK"
-		if err != nil {
-			statusCode = "ERROR"
-		}
-
-		grpcLog.Info(newCtx,
-			"gRPC client request",
-			"method", method,
-			"duration", duration,
-			"status", statusCode,
-		)
+		lt.logGRPCCall(newCtx, options, "gRPC client request", method, time.Since(startTime), err, mdArgs)
 
 		return err
 	}
 }
 
-// StreamClientInterceptor returns a gRPC stream client interceptor for logging and tracing
-func (lt *LogTracer) StreamClientInterceptor() grpc.StreamClientInterceptor {
+// StreamClientInterceptor returns a gRPC stream client interceptor for logging
+// and tracing. The span ends and the call is logged when the stream completes.
+func (lt *LogTracer) StreamClientInterceptor(opts ...GRPCOption) grpc.StreamClientInterceptor {
+	options := newGRPCOptions(opts)
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		lt := lt.orDefault()
 		startTime := time.Now()
 
-		newCtx := StartSpan(ctx, method)
-		defer EndSpan(newCtx)
+		newCtx := lt.startCallSpan(ctx, options, method)
 
 		AddAttribute(newCtx, "grpc.method", method)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		mdArgs := options.metadataArgs(newCtx, md)
+		newCtx = lt.injectOutgoing(newCtx, options)
+
+		stats := newStreamStats(newCtx, options.MessageEvents)
+		finish := func(err error) {
+			lt.logGRPCCall(newCtx, options, "gRPC client stream", method, time.Since(startTime), err, append(mdArgs, stats.args()...))
+			EndSpan(newCtx)
+		}
 
 		clientStream, err := streamer(newCtx, desc, cc, method, opts...)
-
-		duration := time.Since(startTime)
-		statusCode := "OK"
 		if err != nil {
-			statusCode = "ERROR"
+			finish(err)
+			return nil, err
 		}
-
-		grpcLog.Info(newCtx,
-			"gRPC client stream",
-			"method", method,
-			"duration", duration,
-			"status", statusCode,
-		)
-
-		return clientStream, err
+		return newWrappedClientStream(newCtx, clientStream, desc, stats, finish), nil
 	}
 }
 
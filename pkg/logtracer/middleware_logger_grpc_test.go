@@ -1,8 +1,26 @@
 package logtracer
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"log/slog"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestGRPCInterceptors(t *testing.T) {
@@ -34,3 +52,380 @@ func TestGRPCInterceptors(t *testing.T) {
 		assert.NotNil(t, clientStream)
 	})
 }
+
+func TestGRPCInterceptorMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+		serviceName: "test-service",
+		propagator:  propagation.TraceContext{},
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+
+	interceptor := lt.UnaryServerInterceptor(WithMetadata("x-tenant", "authorization", "x-session"), WithSensitiveMetadata("x-session"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-tenant", "acme",
+		"authorization", "Bearer secret",
+		"x-session", "abc",
+	))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, err := interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	assert.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "secret")
+	checkLogOutput(t, buf.String(), `{"msg":"gRPC request","category":"GRPC","headers":{"x-tenant":"acme","authorization":"[REDACTED]","x-session":"[REDACTED]"}}`)
+}
+
+func TestGRPCInterceptorStatusCodes(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		serviceName: "test-service",
+		propagator:  propagation.TraceContext{},
+		tracer:      tp.Tracer("test"),
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+
+	tests := []struct {
+		name string
+		opts []GRPCOption
+		err  error
+		want string
+	}{
+		{
+			name: "OK",
+			want: `{"level":"INFO","status":"OK","code":0}`,
+		},
+		{
+			name: "NotFound",
+			err:  status.Error(codes.NotFound, "user missing"),
+			want: `{"level":"WARN","status":"NotFound","code":5,"error":"user missing"}`,
+		},
+		{
+			name: "Internal",
+			err:  status.Error(codes.Internal, "boom"),
+			want: `{"level":"ERROR","status":"Internal","code":13,"error":"boom"}`,
+		},
+		{
+			name: "Plain error",
+			err:  errors.New("plain"),
+			want: `{"level":"ERROR","status":"Unknown","code":2,"error":"plain"}`,
+		},
+		{
+			name: "Custom level",
+			opts: []GRPCOption{WithCodeLevel(codes.NotFound, LevelDebug)},
+			err:  status.Error(codes.NotFound, "user missing"),
+			want: `{"level":"DEBUG","status":"NotFound","code":5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			exporter.Reset()
+			interceptor := lt.UnaryServerInterceptor(tt.opts...)
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+			_, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			assert.Equal(t, tt.err, err)
+			checkLogOutput(t, buf.String(), tt.want)
+
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			st, _ := status.FromError(tt.err)
+			assert.Contains(t, spans[0].Attributes, attribute.Int("rpc.grpc.status_code", int(st.Code())))
+			if tt.err != nil {
+				assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+				assert.Equal(t, st.Message(), spans[0].Status.Description)
+			}
+		})
+	}
+}
+
+func TestGRPCClientInterceptorInjectsContext(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	propagator, err := newPropagator([]string{PropagatorTraceContext, PropagatorBaggage, PropagatorB3})
+	assert.NoError(t, err)
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)),
+		serviceName: "test-service",
+		customID:    "sessionID",
+		propagator:  propagator,
+		tracer:      tp.Tracer("test"),
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+
+	ctx := context.WithValue(context.Background(), "sessionID", "session-42")
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant", "acme")
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	err = lt.UnaryClientInterceptor()(ctx, "/test.Service/Method", "req", nil, nil, invoker)
+	assert.NoError(t, err)
+
+	assert.Len(t, sent.Get("traceparent"), 1)
+	assert.Len(t, sent.Get("b3"), 1)
+	assert.Equal(t, []string{"session-42"}, sent.Get("x-request-id"))
+	assert.Equal(t, []string{"acme"}, sent.Get("x-tenant"))
+
+	// The server side restores the custom ID from the same metadata.
+	server := &LogTracer{
+		logger:      lt.logger,
+		serviceName: "server",
+		customID:    "sessionID",
+		propagator:  propagator,
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	server.grpcLog = server.RegisterCategory("GRPC")
+	var got string
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, err = server.UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), sent), "req", info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = server.GetCustomID(ctx)
+			return nil, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "session-42", got)
+}
+
+func TestGRPCServerInterceptorExtractsContext(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	propagator, err := newPropagator([]string{PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorJaeger})
+	assert.NoError(t, err)
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil)),
+		serviceName: "test-service",
+		propagator:  propagator,
+		tracer:      tp.Tracer("test"),
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name string
+		md   metadata.MD
+	}{
+		{"W3C traceparent", metadata.Pairs("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")},
+		{"B3 single", metadata.Pairs("b3", traceID+"-00f067aa0ba902b7-1")},
+		{"B3 multi", metadata.Pairs("x-b3-traceid", traceID, "x-b3-spanid", "00f067aa0ba902b7", "x-b3-sampled", "1")},
+		{"Jaeger", metadata.Pairs("uber-trace-id", traceID+":00f067aa0ba902b7:0:1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got trace.SpanContext
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				got = trace.SpanContextFromContext(ctx)
+				return nil, nil
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+			_, err := lt.UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), tt.md), "req", info, handler)
+			assert.NoError(t, err)
+			assert.Equal(t, traceID, got.TraceID().String())
+			assert.NotEqual(t, "00f067aa0ba902b7", got.SpanID().String())
+		})
+	}
+}
+
+func TestGRPCServerInterceptorIgnoresCustomIDWithoutKey(t *testing.T) {
+	var buf bytes.Buffer
+	lt, _ := newStreamTestTracer(t, &buf)
+
+	var empty any
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		empty = ctx.Value("")
+		return nil, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "client-id"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, err := lt.UnaryServerInterceptor()(ctx, "req", info, handler)
+	assert.NoError(t, err)
+
+	assert.Nil(t, empty)
+	assert.NotContains(t, buf.String(), "client-id")
+	assert.Regexp(t, `"id":"[0-9a-f]{32}"`, buf.String())
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv []string
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
+func (f *fakeServerStream) SendMsg(m any) error      { return nil }
+func (f *fakeServerStream) RecvMsg(m any) error {
+	if len(f.recv) == 0 {
+		return io.EOF
+	}
+	m.(*wrapperspb.StringValue).Value, f.recv = f.recv[0], f.recv[1:]
+	return nil
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recv []string
+	err  error
+}
+
+func (f *fakeClientStream) SendMsg(m any) error { return nil }
+func (f *fakeClientStream) CloseSend() error    { return nil }
+func (f *fakeClientStream) RecvMsg(m any) error {
+	if len(f.recv) == 0 {
+		return f.err
+	}
+	m.(*wrapperspb.StringValue).Value, f.recv = f.recv[0], f.recv[1:]
+	return nil
+}
+
+func newStreamTestTracer(t *testing.T, buf *bytes.Buffer) (*LogTracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	lt := &LogTracer{
+		logger:      slog.New(slog.NewJSONHandler(buf, nil)),
+		serviceName: "test-service",
+		propagator:  propagation.TraceContext{},
+		tracer:      tp.Tracer("test"),
+		Categories:  make(map[string]*CategoryLogger),
+	}
+	lt.grpcLog = lt.RegisterCategory("GRPC")
+	return lt, exporter
+}
+
+func TestGRPCStreamServerInterceptorCountsMessages(t *testing.T) {
+	var buf bytes.Buffer
+	lt, exporter := newStreamTestTracer(t, &buf)
+
+	ss := &fakeServerStream{ctx: context.Background(), recv: []string{"a", "bc"}}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	err := lt.StreamServerInterceptor(WithMessageEvents())(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+		for {
+			msg := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(msg); err != nil {
+				break
+			}
+			_ = stream.SendMsg(msg)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	checkLogOutput(t, buf.String(), `{"msg":"gRPC stream","logtracer.rpc.messages_sent":2,"logtracer.rpc.messages_received":2,"logtracer.rpc.bytes_sent":7,"logtracer.rpc.bytes_received":7}`)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes, attribute.Int64("logtracer.rpc.messages_received", 2))
+	// Two messages each way plus the "log" event of the GRPC line.
+	assert.Len(t, spans[0].Events, 5)
+	assert.Equal(t, "message", spans[0].Events[0].Name)
+	assert.Contains(t, spans[0].Events[0].Attributes, attribute.String("message.type", "RECEIVED"))
+}
+
+func TestGRPCStreamClientInterceptorLifetime(t *testing.T) {
+	desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+
+	t.Run("Ends on EOF", func(t *testing.T) {
+		var buf bytes.Buffer
+		lt, exporter := newStreamTestTracer(t, &buf)
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{recv: []string{"hello"}, err: io.EOF}, nil
+		}
+		cs, err := lt.StreamClientInterceptor()(context.Background(), desc, nil, "/test.Service/Stream", streamer)
+		assert.NoError(t, err)
+
+		assert.NoError(t, cs.SendMsg(wrapperspb.String("hi")))
+		assert.NoError(t, cs.CloseSend())
+		assert.NoError(t, cs.RecvMsg(&wrapperspb.StringValue{}))
+		assert.Empty(t, buf.String())
+		assert.Empty(t, exporter.GetSpans())
+
+		assert.Equal(t, io.EOF, cs.RecvMsg(&wrapperspb.StringValue{}))
+		checkLogOutput(t, buf.String(), `{"msg":"gRPC client stream","status":"OK","logtracer.rpc.messages_sent":1,"logtracer.rpc.messages_received":1,"logtracer.rpc.bytes_sent":4,"logtracer.rpc.bytes_received":7}`)
+		assert.Len(t, exporter.GetSpans(), 1)
+
+		// Further calls do not log again.
+		buf.Reset()
+		_ = cs.RecvMsg(&wrapperspb.StringValue{})
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Ends on error", func(t *testing.T) {
+		var buf bytes.Buffer
+		lt, exporter := newStreamTestTracer(t, &buf)
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{err: status.Error(codes.Unavailable, "gone")}, nil
+		}
+		cs, err := lt.StreamClientInterceptor()(context.Background(), desc, nil, "/test.Service/Stream", streamer)
+		assert.NoError(t, err)
+
+		assert.Error(t, cs.RecvMsg(&wrapperspb.StringValue{}))
+		checkLogOutput(t, buf.String(), `{"level":"ERROR","msg":"gRPC client stream","status":"Unavailable","error":"gone"}`)
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+	})
+
+	t.Run("Ends on context cancel", func(t *testing.T) {
+		var buf bytes.Buffer
+		lt, exporter := newStreamTestTracer(t, &buf)
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{}, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := lt.StreamClientInterceptor()(ctx, desc, nil, "/test.Service/Stream", streamer)
+		assert.NoError(t, err)
+
+		cancel()
+		assert.Eventually(t, func() bool { return len(exporter.GetSpans()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Contains(t, exporter.GetSpans()[0].Attributes, attribute.Int("rpc.grpc.status_code", int(codes.Canceled)))
+	})
+
+	t.Run("Open streams keep no goroutine", func(t *testing.T) {
+		var buf bytes.Buffer
+		lt, _ := newStreamTestTracer(t, &buf)
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{}, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		before := runtime.NumGoroutine()
+		for i := 0; i < 50; i++ {
+			_, err := lt.StreamClientInterceptor()(ctx, desc, nil, "/test.Service/Stream", streamer)
+			assert.NoError(t, err)
+		}
+		assert.Less(t, runtime.NumGoroutine()-before, 10)
+	})
+
+	t.Run("Ends when the stream cannot be opened", func(t *testing.T) {
+		var buf bytes.Buffer
+		lt, exporter := newStreamTestTracer(t, &buf)
+		streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.Unavailable, "no connection")
+		}
+		_, err := lt.StreamClientInterceptor()(context.Background(), desc, nil, "/test.Service/Stream", streamer)
+		assert.Error(t, err)
+		checkLogOutput(t, buf.String(), `{"msg":"gRPC client stream","status":"Unavailable"}`)
+		assert.Len(t, exporter.GetSpans(), 1)
+	})
+}
@@ -0,0 +1,70 @@
+package logtracer
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"runtime/debug"
+)
+
+type RecoveryOption func(*RecoveryOptions)
+
+type RecoveryOptions struct {
+	// Handler writes the response after a panic was logged. The default
+	// aborts with 500 and {"error":"internal server error"}.
+	Handler func(c *gin.Context, recovered any)
+}
+
+// WithRecoveryHandler replaces the default 500 response written by GinRecovery.
+func WithRecoveryHandler(handler func(c *gin.Context, recovered any)) RecoveryOption {
+	return func(o *RecoveryOptions) {
+		o.Handler = handler
+	}
+}
+
+// GinRecovery recovers panics in handlers, logs them with their stack through
+// the GIN category of the default instance and records them on the active
+// span. Register it after OTELMiddleware and GinMiddleware so the log line
+// carries the request IDs and the request is still logged with its 500 status.
+func GinRecovery(opts ...RecoveryOption) gin.HandlerFunc {
+	return ginRecovery(defaultOrFallback, opts)
+}
+
+// GinRecovery is GinRecovery logging through the GIN category of this instance.
+func (lt *LogTracer) GinRecovery(opts ...RecoveryOption) gin.HandlerFunc {
+	return ginRecovery(lt.orDefault, opts)
+}
+
+func ginRecovery(owner func() *LogTracer, opts []RecoveryOption) gin.HandlerFunc {
+	options := &RecoveryOptions{Handler: defaultRecoveryHandler}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			ctx := c.Request.Context()
+			owner().ginLog.Error(ctx, "Panic recovered",
+				"panic", recovered,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"stack", string(stack),
+			)
+			recordPanic(ctx, recovered, stack)
+			options.Handler(c, recovered)
+		}()
+		c.Next()
+	}
+}
+
+func defaultRecoveryHandler(c *gin.Context, _ any) {
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+}
@@ -0,0 +1,76 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGinRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	lt := newGinTestTracer(&buf)
+	lt.customID = "requestID"
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinMiddlewareWithConfig(DefaultGinConfig()))
+	r.Use(func(c *gin.Context) {
+		ctx, span := tp.Tracer("test").Start(c.Request.Context(), "request")
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	r.Use(lt.GinRecovery())
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("X-Request-ID", "panic-id")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"internal server error"}`, rec.Body.String())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	checkLogOutput(t, lines[0], `{"level":"ERROR","msg":"Panic recovered","category":"GIN","panic":"boom","id":"panic-id"}`)
+	assert.Contains(t, lines[0], `"stack":"goroutine`)
+	checkLogOutput(t, lines[1], `{"level":"ERROR","msg":"HTTP request","status":500,"id":"panic-id"}`)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	var names []string
+	for _, event := range spans[0].Events {
+		names = append(names, event.Name)
+	}
+	assert.Contains(t, names, "exception")
+}
+
+func TestGinRecoveryCustomHandler(t *testing.T) {
+	lt := newGinTestTracer(&bytes.Buffer{})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(lt.GinRecovery(WithRecoveryHandler(func(c *gin.Context, recovered any) {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"panic": recovered})
+	})))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"panic":"boom"}`, rec.Body.String())
+}
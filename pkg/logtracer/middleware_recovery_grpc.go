@@ -0,0 +1,78 @@
+package logtracer
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+)
+
+type GRPCRecoveryOption func(*GRPCRecoveryOptions)
+
+type GRPCRecoveryOptions struct {
+	// Handler turns a recovered panic into the error returned to the caller.
+	// The default returns codes.Internal with "internal server error".
+	Handler func(ctx context.Context, method string, recovered any) error
+}
+
+// WithGRPCRecoveryHandler replaces the default codes.Internal error returned
+// by the gRPC recovery interceptors.
+func WithGRPCRecoveryHandler(handler func(ctx context.Context, method string, recovered any) error) GRPCRecoveryOption {
+	return func(o *GRPCRecoveryOptions) {
+		o.Handler = handler
+	}
+}
+
+func newGRPCRecoveryOptions(opts []GRPCRecoveryOption) *GRPCRecoveryOptions {
+	options := &GRPCRecoveryOptions{Handler: defaultGRPCRecoveryHandler}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// UnaryServerRecoveryInterceptor recovers panics in unary handlers, logs them
+// with their stack through the GRPC category and records them on the active
+// span. Chain it after UnaryServerInterceptor so the log line carries the
+// trace and custom IDs and the call is still logged with its final status.
+func (lt *LogTracer) UnaryServerRecoveryInterceptor(opts ...GRPCRecoveryOption) grpc.UnaryServerInterceptor {
+	options := newGRPCRecoveryOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = lt.orDefault().recoverGRPC(ctx, options, info.FullMethod, recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecoveryInterceptor is the stream counterpart of
+// UnaryServerRecoveryInterceptor.
+func (lt *LogTracer) StreamServerRecoveryInterceptor(opts ...GRPCRecoveryOption) grpc.StreamServerInterceptor {
+	options := newGRPCRecoveryOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = lt.orDefault().recoverGRPC(ss.Context(), options, info.FullMethod, recovered)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func (lt *LogTracer) recoverGRPC(ctx context.Context, options *GRPCRecoveryOptions, method string, recovered any) error {
+	stack := debug.Stack()
+	lt.grpcLog.Error(ctx, "Panic recovered",
+		"panic", recovered,
+		"method", method,
+		"stack", string(stack),
+	)
+	recordPanic(ctx, recovered, stack)
+	return options.Handler(ctx, method, recovered)
+}
+
+func defaultGRPCRecoveryHandler(context.Context, string, any) error {
+	return status.Error(codes.Internal, "internal server error")
+}
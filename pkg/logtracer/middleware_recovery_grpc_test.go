@@ -0,0 +1,84 @@
+package logtracer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+)
+
+func TestGRPCRecoveryInterceptors(t *testing.T) {
+	var buf bytes.Buffer
+	lt, exporter := newStreamTestTracer(t, &buf)
+	lt.customID = "sessionID"
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "panic-id"))
+
+	t.Run("Unary", func(t *testing.T) {
+		buf.Reset()
+		exporter.Reset()
+		logging, recovery := lt.UnaryServerInterceptor(), lt.UnaryServerRecoveryInterceptor()
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+		_, err := logging(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return recovery(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				panic("boom")
+			})
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+		checkLogOutput(t, lines[0], `{"level":"ERROR","msg":"Panic recovered","category":"GRPC","panic":"boom","method":"/test.Service/Method","id":"panic-id"}`)
+		assert.Contains(t, lines[0], `"stack":"goroutine`)
+		assert.NotContains(t, lines[0], `"trace_id"`)
+		checkLogOutput(t, lines[1], `{"level":"ERROR","msg":"gRPC request","status":"Internal","id":"panic-id"}`)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, otelcodes.Error, spans[0].Status.Code)
+		var names []string
+		for _, event := range spans[0].Events {
+			names = append(names, event.Name)
+		}
+		assert.Contains(t, names, "exception")
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		buf.Reset()
+		recovery := lt.StreamServerRecoveryInterceptor()
+		info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+		err := recovery(nil, &fakeServerStream{ctx: ctx}, info, func(srv any, stream grpc.ServerStream) error {
+			panic(errors.New("stream boom"))
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		checkLogOutput(t, buf.String(), `{"msg":"Panic recovered","panic":"stream boom","method":"/test.Service/Stream"}`)
+	})
+
+	t.Run("Custom handler", func(t *testing.T) {
+		recovery := lt.UnaryServerRecoveryInterceptor(WithGRPCRecoveryHandler(func(ctx context.Context, method string, recovered any) error {
+			return status.Errorf(codes.Unavailable, "%s: %v", method, recovered)
+		}))
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+		_, err := recovery(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		assert.Equal(t, status.Error(codes.Unavailable, "/test.Service/Method: boom"), err)
+	})
+
+	t.Run("No panic", func(t *testing.T) {
+		recovery := lt.UnaryServerRecoveryInterceptor()
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+		resp, err := recovery(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+}
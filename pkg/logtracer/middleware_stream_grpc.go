@@ -0,0 +1,168 @@
+package logtracer
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// streamStats counts the messages and bytes going through a stream and, when
+// events is set, records each message as a span event.
+type streamStats struct {
+	ctx    context.Context
+	events bool
+
+	sent          atomic.Int64
+	received      atomic.Int64
+	sentBytes     atomic.Int64
+	receivedBytes atomic.Int64
+}
+
+func newStreamStats(ctx context.Context, events bool) *streamStats {
+	return &streamStats{ctx: ctx, events: events}
+}
+
+func (s *streamStats) onSend(m any) {
+	size := messageSize(m)
+	id := s.sent.Add(1)
+	s.sentBytes.Add(int64(size))
+	if s.events {
+		messageEvent(s.ctx, otelgrpc.RPCMessageTypeSent, id, size)
+	}
+}
+
+func (s *streamStats) onRecv(m any) {
+	size := messageSize(m)
+	id := s.received.Add(1)
+	s.receivedBytes.Add(int64(size))
+	if s.events {
+		messageEvent(s.ctx, otelgrpc.RPCMessageTypeReceived, id, size)
+	}
+}
+
+// messageEvent adds a "message" event with the otelgrpc attribute names.
+func messageEvent(ctx context.Context, kind attribute.KeyValue, id int64, size int) {
+	trace.SpanFromContext(ctx).AddEvent("message", trace.WithAttributes(
+		kind,
+		otelgrpc.RPCMessageIDKey.Int64(id),
+		otelgrpc.RPCMessageUncompressedSizeKey.Int(size),
+	))
+}
+
+// args records the totals on the span and returns them as log arguments.
+func (s *streamStats) args() []any {
+	return spanArgs(s.ctx,
+		attribute.Int64("logtracer.rpc.messages_sent", s.sent.Load()),
+		attribute.Int64("logtracer.rpc.messages_received", s.received.Load()),
+		attribute.Int64("logtracer.rpc.bytes_sent", s.sentBytes.Load()),
+		attribute.Int64("logtracer.rpc.bytes_received", s.receivedBytes.Load()),
+	)
+}
+
+// messageSize returns the encoded size of protobuf messages and 0 otherwise.
+func messageSize(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
+
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	stats *streamStats
+}
+
+func (w *wrappedServerStream) Context() context.Context {
+	return w.ctx
+}
+
+func (w *wrappedServerStream) SendMsg(m any) error {
+	err := w.ServerStream.SendMsg(m)
+	if err == nil {
+		w.stats.onSend(m)
+	}
+	return err
+}
+
+func (w *wrappedServerStream) RecvMsg(m any) error {
+	err := w.ServerStream.RecvMsg(m)
+	if err == nil {
+		w.stats.onRecv(m)
+	}
+	return err
+}
+
+// wrappedClientStream calls finish once the stream is over: when RecvMsg
+// returns io.EOF or an error, after the single response of a non server
+// streaming call, or when the call context is done. The context is watched
+// with context.AfterFunc, so no goroutine is kept per open stream.
+type wrappedClientStream struct {
+	grpc.ClientStream
+	desc  *grpc.StreamDesc
+	stats *streamStats
+
+	once   sync.Once
+	stop   func() bool
+	finish func(err error)
+}
+
+func newWrappedClientStream(ctx context.Context, cs grpc.ClientStream, desc *grpc.StreamDesc, stats *streamStats, finish func(err error)) *wrappedClientStream {
+	w := &wrappedClientStream{
+		ClientStream: cs,
+		desc:         desc,
+		stats:        stats,
+		finish:       finish,
+	}
+	w.stop = context.AfterFunc(ctx, func() {
+		w.end(status.FromContextError(ctx.Err()).Err())
+	})
+	return w
+}
+
+func (w *wrappedClientStream) SendMsg(m any) error {
+	err := w.ClientStream.SendMsg(m)
+	if err == nil {
+		w.stats.onSend(m)
+	} else if !errors.Is(err, io.EOF) {
+		// io.EOF means the server ended the stream; its status comes from RecvMsg.
+		w.done(err)
+	}
+	return err
+}
+
+func (w *wrappedClientStream) RecvMsg(m any) error {
+	err := w.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		w.stats.onRecv(m)
+		if !w.desc.ServerStreams {
+			w.done(nil)
+		}
+	case errors.Is(err, io.EOF):
+		w.done(nil)
+	default:
+		w.done(err)
+	}
+	return err
+}
+
+// done ends the stream from SendMsg or RecvMsg and stops watching the context.
+func (w *wrappedClientStream) done(err error) {
+	w.stop()
+	w.end(err)
+}
+
+func (w *wrappedClientStream) end(err error) {
+	w.once.Do(func() {
+		w.finish(err)
+	})
+}
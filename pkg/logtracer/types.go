@@ -1,10 +1,13 @@
 package logtracer
 
 import (
+	"github.com/rafapcarvalho/logtracer/internal/handlers"
 	"go.opentelemetry.io/otel/propagation"
 	provider "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	tracer "go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"sync"
 )
 
 type CustomID string
@@ -15,15 +18,28 @@ func (id CustomID) String() string {
 
 type LogTracer struct {
 	logger         *slog.Logger
+	level          *slog.LevelVar
+	serviceName    string
+	customID       CustomID
 	tracerProvider *provider.TracerProvider
 	tracer         tracer.Tracer
 	propagator     propagation.TextMapPropagator
+	memoryExporter *tracetest.InMemoryExporter
+	tailSampler    *tailSamplingProcessor
+	exportCounters *exportCounters
+	stopStats      chan struct{}
+	stopStatsOnce  sync.Once
+	redactor       *handlers.Redactor
+	ginLog         *CategoryLogger
+	grpcLog        *CategoryLogger
+	noTrace        *CategoryLogger
 	InitLog        *CategoryLogger
 	CfgLog         *CategoryLogger
 	SrvcLog        *CategoryLogger
 	TstLog         *CategoryLogger
 	NoTrace        WithoutTracer
 	Categories     map[string]*CategoryLogger
+	mu             sync.RWMutex
 }
 
 type SpanOption func(*SpanOptions)
@@ -55,4 +71,27 @@ type Config struct {
 	EnableTracing      bool
 	OTLPEndpoint       string
 	AdditionalResource map[string]string
+	Redaction          *RedactionConfig
+	// Propagators selects the context propagation formats, by name:
+	// tracecontext, baggage, b3 (single header), b3multi and jaeger. Defaults
+	// to tracecontext and baggage. Any B3 form is accepted on extraction.
+	Propagators []string
+	// Exporter selects where spans are sent when tracing is enabled:
+	// otlp-http (default), otlp-grpc, stdout, file (JSON lines appended to
+	// ExporterPath), memory (read back with RecordedSpans) or none.
+	// SpanExporter, when set, is used instead.
+	Exporter     string
+	ExporterPath string
+	SpanExporter provider.SpanExporter
+	// OTLP holds TLS, headers, compression, timeout and retry settings for
+	// the otlp-http and otlp-grpc exporters.
+	OTLP *OTLPConfig
+	// Sampling chooses which traces are recorded; every trace is sampled by
+	// default unless OTEL_TRACES_SAMPLER says otherwise.
+	Sampling *SamplingConfig
+	// TailSampling buffers spans per trace and exports every failing trace
+	// but only a ratio of the healthy ones.
+	TailSampling *TailSamplingConfig
+	// Batch tunes the batch span processor and the periodic export stats.
+	Batch *BatchConfig
 }